@@ -0,0 +1,109 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// pumpFunApiUrl is the URL of the pump.fun frontend API, used for the data that isn't on-chain.
+var pumpFunApiUrl = "https://frontend-api.pump.fun"
+
+// apiPageSize is the number of items requested per page from the pump.fun API.
+const apiPageSize = 50
+
+// CreatorToken is a token created by a wallet, as returned by the pump.fun API.
+type CreatorToken struct {
+	Mint        solana.PublicKey `json:"mint"`
+	Name        string           `json:"name"`
+	Symbol      string           `json:"symbol"`
+	Description string           `json:"description"`
+	ImageUri    string           `json:"image_uri"`
+	MetadataUri string           `json:"metadata_uri"`
+	// CreatedTimestamp is the creation time, in milliseconds since the Unix epoch.
+	CreatedTimestamp int64 `json:"created_timestamp"`
+	// Complete is true once the bonding curve completed, and the token migrated to Raydium.
+	Complete     bool    `json:"complete"`
+	UsdMarketCap float64 `json:"usd_market_cap"`
+}
+
+// GetCreatorTokens returns the tokens created by the wallet, from the pump.fun API, as the bonding curves
+// don't record their creator on-chain. It helps to spot serial ruggers.
+func GetCreatorTokens(ctx context.Context, httpClient *http.Client, creator solana.PublicKey) ([]CreatorToken, error) {
+	var tokens []CreatorToken
+	for offset := 0; ; offset += apiPageSize {
+		query := url.Values{
+			"offset":      {strconv.Itoa(offset)},
+			"limit":       {strconv.Itoa(apiPageSize)},
+			"includeNsfw": {"true"},
+		}
+		var page []CreatorToken
+		if err := getPumpFunApi(ctx, httpClient, "/coins/user-created-coins/"+creator.String(), query, &page); err != nil {
+			return nil, fmt.Errorf("can't get tokens created by %s: %w", creator, err)
+		}
+		tokens = append(tokens, page...)
+		if len(page) < apiPageSize {
+			return tokens, nil
+		}
+	}
+}
+
+// RecentTrade is a trade of a token, as returned by the pump.fun API.
+type RecentTrade struct {
+	Signature   solana.Signature `json:"signature"`
+	Mint        solana.PublicKey `json:"mint"`
+	User        solana.PublicKey `json:"user"`
+	IsBuy       bool             `json:"is_buy"`
+	SolAmount   Lamports         `json:"sol_amount"`
+	TokenAmount TokenAmount      `json:"token_amount"`
+	// Timestamp is the time of the trade, in seconds since the Unix epoch.
+	Timestamp int64  `json:"timestamp"`
+	Slot      uint64 `json:"slot"`
+}
+
+// GetRecentTrades returns up to limit of the most recent trades of the mint, the most recent first,
+// from the pump.fun API, e.g. to backfill a chart before watching the new trades with WatchTrades.
+func GetRecentTrades(ctx context.Context, httpClient *http.Client, mint solana.PublicKey, limit int) ([]RecentTrade, error) {
+	var trades []RecentTrade
+	for offset := 0; offset < limit; offset += apiPageSize {
+		pageSize := min(apiPageSize, limit-offset)
+		query := url.Values{
+			"offset":      {strconv.Itoa(offset)},
+			"limit":       {strconv.Itoa(pageSize)},
+			"minimumSize": {"0"},
+		}
+		var page []RecentTrade
+		if err := getPumpFunApi(ctx, httpClient, "/trades/all/"+mint.String(), query, &page); err != nil {
+			return nil, fmt.Errorf("can't get trades of %s: %w", mint, err)
+		}
+		trades = append(trades, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return trades, nil
+}
+
+// getPumpFunApi gets the path from the pump.fun API, and decodes the JSON response into out.
+func getPumpFunApi(ctx context.Context, httpClient *http.Client, path string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pumpFunApiUrl+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", defaultUserAgent)
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,89 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestGetCreatorTokens(t *testing.T) {
+	creator := solana.NewWallet().PublicKey()
+	// One full page, then a partial one.
+	const total = apiPageSize + 3
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coins/user-created-coins/"+creator.String() {
+			http.NotFound(w, r)
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var page []CreatorToken
+		for i := offset; i < min(offset+apiPageSize, total); i++ {
+			page = append(page, CreatorToken{Mint: solana.NewWallet().PublicKey(), Name: strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+	defer func(url string) { pumpFunApiUrl = url }(pumpFunApiUrl)
+	pumpFunApiUrl = server.URL
+
+	tokens, err := GetCreatorTokens(context.Background(), server.Client(), creator)
+	if err != nil {
+		t.Fatalf("GetCreatorTokens() error = %s", err)
+	}
+	if len(tokens) != total {
+		t.Fatalf("GetCreatorTokens() returned %d tokens, want %d", len(tokens), total)
+	}
+	if tokens[total-1].Name != strconv.Itoa(total-1) {
+		t.Fatalf("GetCreatorTokens() last token = %q, want %q", tokens[total-1].Name, strconv.Itoa(total-1))
+	}
+}
+
+func TestGetRecentTrades(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	// Fewer trades than requested.
+	const total = apiPageSize + 3
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trades/all/"+mint.String() {
+			http.NotFound(w, r)
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var page []map[string]any
+		for i := offset; i < min(offset+limit, total); i++ {
+			page = append(page, map[string]any{"mint": mint.String(), "is_buy": true, "sol_amount": 100000000, "timestamp": i})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+	defer func(url string) { pumpFunApiUrl = url }(pumpFunApiUrl)
+	pumpFunApiUrl = server.URL
+
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{"less than a page", 10, 10},
+		{"all", 100, total},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades, err := GetRecentTrades(context.Background(), server.Client(), mint, tt.limit)
+			if err != nil {
+				t.Fatalf("GetRecentTrades() error = %s", err)
+			}
+			if len(trades) != tt.want {
+				t.Fatalf("GetRecentTrades() returned %d trades, want %d", len(trades), tt.want)
+			}
+			if last := trades[len(trades)-1]; last.Timestamp != int64(tt.want-1) || last.SolAmount != 100000000 || !last.IsBuy {
+				t.Fatalf("GetRecentTrades() last trade = %+v", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package pumpdotfunsdk
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// findAssociatedTokenAddress returns the associated token account of the wallet for the mint,
+// owned by the token program.
+func findAssociatedTokenAddress(wallet solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey) (solana.PublicKey, error) {
+	ata, _, err := solana.FindProgramAddress([][]byte{
+		wallet[:],
+		tokenProgram[:],
+		mint[:],
+	}, solana.SPLAssociatedTokenAccountProgramID)
+	if err != nil {
+		return solana.PublicKey{}, fmt.Errorf("failed to derive associated token account: %w", err)
+	}
+	return ata, nil
+}
+
+// newCreateAtaInstruction returns the instruction creating the associated token account of the wallet for the mint,
+// owned by the token program.
+func newCreateAtaInstruction(payer solana.PublicKey, wallet solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey) (solana.Instruction, error) {
+	if tokenProgram.Equals(token.ProgramID) {
+		return associatedtokenaccount.NewCreateInstruction(payer, wallet, mint).ValidateAndBuild()
+	}
+	ata, err := findAssociatedTokenAddress(wallet, mint, tokenProgram)
+	if err != nil {
+		return nil, err
+	}
+	return solana.NewInstruction(
+		solana.SPLAssociatedTokenAccountProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(ata).WRITE(),
+			solana.Meta(wallet),
+			solana.Meta(mint),
+			solana.Meta(system.ProgramID),
+			solana.Meta(tokenProgram),
+		},
+		[]byte{},
+	), nil
+}
+
+// closeAccountInstructionIndex is the index of the CloseAccount instruction, the same in both token programs.
+const closeAccountInstructionIndex = 9
+
+// newCloseAccountInstruction returns the instruction closing the empty token account, owned by the token program,
+// and sending its rent to the destination.
+func newCloseAccountInstruction(account solana.PublicKey, destination solana.PublicKey, owner solana.PublicKey, tokenProgram solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(
+		tokenProgram,
+		solana.AccountMetaSlice{
+			solana.Meta(account).WRITE(),
+			solana.Meta(destination).WRITE(),
+			solana.Meta(owner).SIGNER(),
+		},
+		[]byte{closeAccountInstructionIndex},
+	)
+}
+
+// getBondingCurvePublicKeys returns the bonding curve of the mint, and its associated bonding curve
+// owned by the token program of the options.
+func getBondingCurvePublicKeys(mint solana.PublicKey, o *options) (*BondingCurvePublicKeys, error) {
+	publicKeys, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil || o.getTokenProgram().Equals(token.ProgramID) {
+		return publicKeys, err
+	}
+	associatedBondingCurve, err := findAssociatedTokenAddress(publicKeys.BondingCurve, mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	return &BondingCurvePublicKeys{
+		BondingCurve:           publicKeys.BondingCurve,
+		AssociatedBondingCurve: associatedBondingCurve,
+	}, nil
+}
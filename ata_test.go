@@ -0,0 +1,133 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestFindAssociatedTokenAddress(t *testing.T) {
+	wallet := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	want, _, err := solana.FindAssociatedTokenAddress(wallet, mint)
+	if err != nil {
+		t.Fatalf("FindAssociatedTokenAddress() error = %s", err)
+	}
+	got, err := findAssociatedTokenAddress(wallet, mint, token.ProgramID)
+	if err != nil {
+		t.Fatalf("findAssociatedTokenAddress() error = %s", err)
+	}
+	if got != want {
+		t.Fatalf("findAssociatedTokenAddress() = %s, want %s", got, want)
+	}
+	token2022, err := findAssociatedTokenAddress(wallet, mint, solana.Token2022ProgramID)
+	if err != nil {
+		t.Fatalf("findAssociatedTokenAddress() error = %s", err)
+	}
+	if token2022 == want {
+		t.Fatal("findAssociatedTokenAddress() with Token-2022 = the SPL token ATA, want another address")
+	}
+}
+
+func TestWithdrawInstructionTokenProgram(t *testing.T) {
+	authority := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	tests := []struct {
+		name         string
+		tokenProgram solana.PublicKey
+	}{
+		{"SPL token", token.ProgramID},
+		{"Token-2022", solana.Token2022ProgramID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions([]Option{WithTokenProgram(tt.tokenProgram)})
+			bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ata, err := findAssociatedTokenAddress(authority, mint, tt.tokenProgram)
+			if err != nil {
+				t.Fatal(err)
+			}
+			withdraw, err := WithdrawInstruction(authority, mint, WithTokenProgram(tt.tokenProgram))
+			if err != nil {
+				t.Fatalf("WithdrawInstruction() error = %s", err)
+			}
+			accounts := withdraw.Accounts()
+			if !accounts[3].PublicKey.Equals(bondingCurveData.AssociatedBondingCurve) || !accounts[4].PublicKey.Equals(ata) || !accounts[7].PublicKey.Equals(tt.tokenProgram) {
+				t.Fatalf("WithdrawInstruction() accounts = %v, want the associated accounts of %s", accounts, tt.tokenProgram)
+			}
+		})
+	}
+}
+
+func TestNewCloseAccountInstruction(t *testing.T) {
+	account := solana.NewWallet().PublicKey()
+	owner := solana.NewWallet().PublicKey()
+	want, err := token.NewCloseAccountInstruction(account, owner, owner, []solana.PublicKey{}).ValidateAndBuild()
+	if err != nil {
+		t.Fatalf("NewCloseAccountInstruction() error = %s", err)
+	}
+	wantData, err := want.Data()
+	if err != nil {
+		t.Fatalf("can't get instruction data: %s", err)
+	}
+	got := newCloseAccountInstruction(account, owner, owner, token.ProgramID)
+	gotData, err := got.Data()
+	if err != nil {
+		t.Fatalf("can't get instruction data: %s", err)
+	}
+	if !bytes.Equal(gotData, wantData) {
+		t.Fatalf("newCloseAccountInstruction() data = %v, want %v", gotData, wantData)
+	}
+}
+
+// nilValueRPCClient answers with a nil value for the accounts that don't exist, as some RPC providers do.
+type nilValueRPCClient struct {
+	*mockRPCClient
+}
+
+func (m nilValueRPCClient) GetAccountInfo(ctx context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	out, err := m.mockRPCClient.GetAccountInfo(ctx, account)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return &rpc.GetAccountInfoResult{}, nil
+	}
+	return out, err
+}
+
+func TestShouldCreateAta(t *testing.T) {
+	existing := solana.NewWallet().PublicKey()
+	missing := solana.NewWallet().PublicKey()
+	mock := &mockRPCClient{accounts: map[solana.PublicKey][]byte{existing: make([]byte, tokenAccountSize)}}
+	tests := []struct {
+		name      string
+		rpcClient RPCClient
+		ata       solana.PublicKey
+		want      bool
+	}{
+		{"existing", mock, existing, false},
+		{"not found", mock, missing, true},
+		{"nil value", nilValueRPCClient{mock}, missing, true},
+		{"existing with nil value client", nilValueRPCClient{mock}, existing, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shouldCreateAta(context.Background(), tt.rpcClient, tt.ata)
+			if err != nil {
+				t.Fatalf("shouldCreateAta() error = %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("shouldCreateAta() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+	if _, err := shouldCreateAta(context.Background(), &mockRPCClient{err: errors.New("rpc down")}, existing); err == nil {
+		t.Fatal("shouldCreateAta() error = nil, want the RPC error")
+	}
+}
@@ -2,45 +2,243 @@ package pumpdotfunsdk
 
 import (
 	"context"
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
+	"time"
 
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
 )
 
+// initialRealTokenReserves is the InitialRealTokenReserves of the mainnet pump.fun global account,
+// i.e. the amount of tokens that can be bought from a bonding curve before it completes.
+// It is only a fallback for when the global account isn't available, see BondingCurveProgressWithGlobal.
+const initialRealTokenReserves = 793100000000000
+
 // BondingCurveData holds the relevant information decoded from the on-chain data.
 type BondingCurveData struct {
 	RealTokenReserves    *big.Int
 	VirtualTokenReserves *big.Int
 	VirtualSolReserves   *big.Int
+	// RealSolReserves is the SOL paid into the bonding curve, withdrawn on migration.
+	RealSolReserves  *big.Int
+	TokenTotalSupply *big.Int
+	// Complete is true once the bonding curve is complete, and the token migrates to Raydium.
+	Complete bool
 }
 
 func (b *BondingCurveData) String() string {
-	return fmt.Sprintf("RealTokenReserves=%s, VirtualTokenReserves=%s, VirtualSolReserves=%s", b.RealTokenReserves, b.VirtualTokenReserves, b.VirtualSolReserves)
+	return fmt.Sprintf("RealTokenReserves=%s, VirtualTokenReserves=%s, VirtualSolReserves=%s, RealSolReserves=%s, TokenTotalSupply=%s, Complete=%t", b.RealTokenReserves, b.VirtualTokenReserves, b.VirtualSolReserves, b.RealSolReserves, b.TokenTotalSupply, b.Complete)
+}
+
+// BondingCurveProgress returns how close the bonding curve is to completion, from 0 to 1.
+// It is the fraction of the initial real token reserves that has been sold, assuming the ones of mainnet.
+// Once it reaches 1, the token migrates to Raydium.
+func BondingCurveProgress(bondingCurve *BondingCurveData) float64 {
+	return bondingCurveProgress(bondingCurve, initialRealTokenReserves)
+}
+
+// BondingCurveProgressWithGlobal returns how close the bonding curve is to completion, from 0 to 1,
+// with the initial real token reserves of the global account, e.g. of a fork or devnet.
+func BondingCurveProgressWithGlobal(bondingCurve *BondingCurveData, global *pump.Global) float64 {
+	return bondingCurveProgress(bondingCurve, global.InitialRealTokenReserves)
+}
+
+// GetBondingCurveProgress fetches the bonding curve of the mint, and the global account,
+// and returns how close the bonding curve is to completion, from 0 to 1. See BondingCurveProgressWithGlobal.
+func GetBondingCurveProgress(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, opts ...Option) (float64, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return 0, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	bondingCurve, err := getBondingCurve(ctx, rpcClient, bondingCurveData.BondingCurve, o)
+	if err != nil {
+		return 0, err
+	}
+	global, err := getGlobal(ctx, rpcClient)
+	if err != nil {
+		return 0, fmt.Errorf("can't get global account: %w", err)
+	}
+	return BondingCurveProgressWithGlobal(bondingCurve, global), nil
+}
+
+// bondingCurveProgress returns the fraction of the initial real token reserves sold from the bonding curve.
+func bondingCurveProgress(bondingCurve *BondingCurveData, initialReserves uint64) float64 {
+	if initialReserves == 0 {
+		return 0
+	}
+	initial := new(big.Float).SetUint64(initialReserves)
+	left := new(big.Float).SetInt(bondingCurve.RealTokenReserves)
+	sold := new(big.Float).Sub(initial, left)
+	progress, _ := new(big.Float).Quo(sold, initial).Float64()
+	return min(max(progress, 0), 1)
+}
+
+// CompletionCost returns the SOL to spend, including the pump.fun fee, to buy all the real token reserves left
+// in the bonding curve, which completes it and migrates the token, and the tokens received for it.
+func CompletionCost(bondingCurve *BondingCurveData, feeBasisPoints uint64) (Lamports, TokenAmount, error) {
+	if bondingCurve.Complete {
+		return 0, 0, ErrBondingCurveComplete
+	}
+	tokens := bondingCurve.RealTokenReserves.Uint64()
+	sol, err := calculateBuyCost(tokens, bondingCurve, feeBasisPoints)
+	if err != nil {
+		return 0, 0, err
+	}
+	return Lamports(sol.Uint64()), TokenAmount(tokens), nil
+}
+
+// GetCompletionCost fetches the bonding curve of the mint, and the fee from the global account,
+// and returns the SOL to spend to complete it, and the tokens received for it. See CompletionCost.
+func GetCompletionCost(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, opts ...Option) (Lamports, TokenAmount, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	bondingCurve, err := getBondingCurve(ctx, rpcClient, bondingCurveData.BondingCurve, o)
+	if err != nil {
+		return 0, 0, err
+	}
+	feeBasisPoints, err := getFeeBasisPoints(ctx, rpcClient, o)
+	if err != nil {
+		return 0, 0, err
+	}
+	return CompletionCost(bondingCurve, feeBasisPoints)
+}
+
+// GetAssociatedBondingCurveBalance returns the amount of tokens held by the associated bonding curve of the mint,
+// i.e. the liquidity available to buy from, and to sell into.
+func GetAssociatedBondingCurveBalance(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, opts ...Option) (TokenAmount, error) {
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, newOptions(opts))
+	if err != nil {
+		return 0, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	balance, err := rpcClient.GetTokenAccountBalance(ctx, bondingCurveData.AssociatedBondingCurve, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, fmt.Errorf("can't get associated bonding curve balance: %w", err)
+	}
+	amount, err := strconv.ParseUint(balance.Value.Amount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("can't convert token amount to integer: %w", err)
+	}
+	return TokenAmount(amount), nil
+}
+
+// getBondingCurve returns the bonding curve of the options if set, fetches it otherwise.
+func getBondingCurve(ctx context.Context, rpcClient RPCClient, bondingCurvePubKey solana.PublicKey, o *options) (*BondingCurveData, error) {
+	if o.bondingCurve != nil {
+		if o.bondingCurve.VirtualSolReserves == nil || o.bondingCurve.VirtualTokenReserves == nil || o.bondingCurve.RealTokenReserves == nil {
+			return nil, errors.New("invalid bonding curve: missing reserves")
+		}
+		return o.bondingCurve, nil
+	}
+	bondingCurve, err := fetchBondingCurve(ctx, rpcClient, bondingCurvePubKey, o.getQuoteCommitment())
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch bonding curve: %w", err)
+	}
+	return bondingCurve, nil
 }
 
 // fetchBondingCurve fetches the bonding curve data from the blockchain and decodes it.
-func fetchBondingCurve(rpcClient *rpc.Client, bondingCurvePubKey solana.PublicKey) (*BondingCurveData, error) {
-	accountInfo, err := rpcClient.GetAccountInfoWithOpts(context.TODO(), bondingCurvePubKey, &rpc.GetAccountInfoOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentProcessed})
+func fetchBondingCurve(ctx context.Context, rpcClient RPCClient, bondingCurvePubKey solana.PublicKey, commitment rpc.CommitmentType) (*BondingCurveData, error) {
+	accountInfo, err := rpcClient.GetAccountInfoWithOpts(ctx, bondingCurvePubKey, &rpc.GetAccountInfoOpts{Encoding: solana.EncodingBase64, Commitment: commitment})
 	if err != nil || accountInfo.Value == nil {
 		return nil, fmt.Errorf("FBCD: failed to get account info: %w", err)
 	}
+	bondingCurve, err := decodeBondingCurve(accountInfo.Value.Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("FBCD: %w", err)
+	}
+	return bondingCurve, nil
+}
+
+// GetBondingCurveData fetches and decodes the bonding curve account, given its own public key rather than the mint,
+// e.g. the BondingCurve of a CreateEvent.
+func GetBondingCurveData(ctx context.Context, rpcClient RPCClient, bondingCurve solana.PublicKey) (*BondingCurveData, error) {
+	return fetchBondingCurve(ctx, rpcClient, bondingCurve, rpc.CommitmentConfirmed)
+}
 
-	data := accountInfo.Value.Data.GetBinary()
-	if len(data) < 24 {
-		return nil, fmt.Errorf("FBCD: insufficient data length")
+// WaitForBondingCurve fetches the bonding curve of the mint every pollInterval until it exists, e.g. when the create event
+// of a new token was seen before the account is visible to the RPC node, and returns it.
+// It returns the error of the context if it's done before.
+func WaitForBondingCurve(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, pollInterval time.Duration) (*BondingCurveData, error) {
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
 	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		bondingCurve, err := fetchBondingCurve(ctx, rpcClient, bondingCurveData.BondingCurve, rpc.CommitmentConfirmed)
+		if err == nil {
+			return bondingCurve, nil
+		}
+		if !errors.Is(err, rpc.ErrNotFound) {
+			return nil, fmt.Errorf("can't fetch bonding curve: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
 
-	// Decode the bonding curve data assuming it follows little-endian format
-	realTokenReserves := big.NewInt(0).SetUint64(binary.LittleEndian.Uint64(data[0:8]))
-	virtualTokenReserves := big.NewInt(0).SetUint64(binary.LittleEndian.Uint64(data[8:16]))
-	virtualSolReserves := big.NewInt(0).SetUint64(binary.LittleEndian.Uint64(data[16:24]))
+// maxMultipleAccounts is the maximum number of accounts the RPC returns in a single getMultipleAccounts call.
+const maxMultipleAccounts = 100
 
+// FetchBondingCurves fetches the bonding curves of multiple mints, using as few RPC calls as possible.
+// The mints without a bonding curve are not present in the returned map.
+func FetchBondingCurves(ctx context.Context, rpcClient RPCClient, mints []solana.PublicKey) (map[solana.PublicKey]*BondingCurveData, error) {
+	bondingCurves := make([]solana.PublicKey, len(mints))
+	for i, mint := range mints {
+		bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+		if err != nil {
+			return nil, fmt.Errorf("can't get bonding curve data of %s: %w", mint, err)
+		}
+		bondingCurves[i] = bondingCurveData.BondingCurve
+	}
+	out := make(map[solana.PublicKey]*BondingCurveData, len(mints))
+	for start := 0; start < len(mints); start += maxMultipleAccounts {
+		end := min(start+maxMultipleAccounts, len(mints))
+		accounts, err := rpcClient.GetMultipleAccountsWithOpts(ctx, bondingCurves[start:end], &rpc.GetMultipleAccountsOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentConfirmed})
+		if err != nil {
+			return nil, fmt.Errorf("can't get bonding curve accounts: %w", err)
+		}
+		for i, account := range accounts.Value {
+			if account == nil {
+				continue
+			}
+			mint := mints[start+i]
+			bondingCurve, err := decodeBondingCurve(account.Data.GetBinary())
+			if err != nil {
+				return nil, fmt.Errorf("can't decode bonding curve of %s: %w", mint, err)
+			}
+			out[mint] = bondingCurve
+		}
+	}
+	return out, nil
+}
+
+// decodeBondingCurve decodes the data of a bonding curve account, with the account type generated from the IDL,
+// so that a change of its layout only needs the pump package to be regenerated.
+func decodeBondingCurve(data []byte) (*BondingCurveData, error) {
+	var bondingCurve pump.BondingCurve
+	if err := bin.NewBorshDecoder(data).Decode(&bondingCurve); err != nil {
+		return nil, fmt.Errorf("can't decode bonding curve: %w", err)
+	}
 	return &BondingCurveData{
-		RealTokenReserves:    realTokenReserves,
-		VirtualTokenReserves: virtualTokenReserves,
-		VirtualSolReserves:   virtualSolReserves,
+		RealTokenReserves:    new(big.Int).SetUint64(bondingCurve.RealTokenReserves),
+		VirtualTokenReserves: new(big.Int).SetUint64(bondingCurve.VirtualTokenReserves),
+		VirtualSolReserves:   new(big.Int).SetUint64(bondingCurve.VirtualSolReserves),
+		RealSolReserves:      new(big.Int).SetUint64(bondingCurve.RealSolReserves),
+		TokenTotalSupply:     new(big.Int).SetUint64(bondingCurve.TokenTotalSupply),
+		Complete:             bondingCurve.Complete,
 	}, nil
 }
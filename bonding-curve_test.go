@@ -0,0 +1,301 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestBondingCurveProgress(t *testing.T) {
+	tests := []struct {
+		name              string
+		realTokenReserves int64
+		want              float64
+	}{
+		{"new", initialRealTokenReserves, 0},
+		{"half", initialRealTokenReserves / 2, 0.5},
+		{"complete", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bondingCurve := &BondingCurveData{RealTokenReserves: big.NewInt(tt.realTokenReserves)}
+			if got := BondingCurveProgress(bondingCurve); got != tt.want {
+				t.Fatalf("BondingCurveProgress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBondingCurveProgressWithGlobal(t *testing.T) {
+	// A fork selling only 400M tokens from its bonding curves.
+	global := &pump.Global{InitialRealTokenReserves: 400000000000000}
+	tests := []struct {
+		name              string
+		realTokenReserves int64
+		want              float64
+	}{
+		{"new", 400000000000000, 0},
+		{"quarter", 300000000000000, 0.25},
+		{"complete", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bondingCurve := &BondingCurveData{RealTokenReserves: big.NewInt(tt.realTokenReserves)}
+			if got := BondingCurveProgressWithGlobal(bondingCurve, global); got != tt.want {
+				t.Fatalf("BondingCurveProgressWithGlobal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateBuyQuote(t *testing.T) {
+	bondingCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(793100000000000),
+		VirtualTokenReserves: big.NewInt(1073000000000000),
+		VirtualSolReserves:   big.NewInt(30000000000),
+	}
+	tests := []struct {
+		name           string
+		solAmount      uint64
+		slippage       uint
+		feeBasisPoints uint64
+		want           int64
+	}{
+		{"without fee", 1000000000, 0, 0, 34612903225807},
+		{"with fee", 1000000000, 0, 100, 34281150129546},
+		// The slippage is rounded down, never to more tokens than the program gives.
+		{"with slippage", 1000000000, 200, 100, 33595527126955},
+		{"tiny amount", 1, 0, 100, 0},
+		// More than the real token reserves, the program rejects such a buy.
+		{"huge amount", math.MaxUint64, 0, 100, 1072999998237527},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateBuyQuote(tt.solAmount, bondingCurve, tt.slippage, tt.feeBasisPoints)
+			if got.Int64() != tt.want {
+				t.Fatalf("calculateBuyQuote() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+	// With a 1% fee, 1.01 SOL buys the same tokens as 1 SOL without fee.
+	withFee := calculateBuyQuote(1010000000, bondingCurve, 0, 100)
+	withoutFee := calculateBuyQuote(1000000000, bondingCurve, 0, 0)
+	if withFee.Cmp(withoutFee) != 0 {
+		t.Fatalf("calculateBuyQuote() with fee = %s, want %s", withFee, withoutFee)
+	}
+	// Buying the tokens costs the SOL they were quoted for, give or take the rounding.
+	cost, err := calculateBuyCost(withFee.Uint64(), bondingCurve, 100)
+	if err != nil {
+		t.Fatalf("calculateBuyCost() error = %s", err)
+	}
+	if cost.Uint64() > 1010000000+2 {
+		t.Fatalf("calculateBuyCost() = %s, want at most %d", cost, 1010000000+2)
+	}
+}
+
+func TestCalculateBuyCostCompleteCurve(t *testing.T) {
+	bondingCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(0),
+		VirtualTokenReserves: big.NewInt(279900000000000),
+		VirtualSolReserves:   big.NewInt(115005359057),
+	}
+	if _, err := calculateBuyCost(1, bondingCurve, 100); err == nil {
+		t.Fatal("calculateBuyCost() on a complete bonding curve, want error")
+	}
+}
+
+func TestGetCompletionCost(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		bondingCurveData.BondingCurve: bondingCurveAccountData(1073000000000000, 30000000000, 793100000000000, 0, 1000000000000000, false),
+	}}
+	sol, tokens, err := GetCompletionCost(context.Background(), rpcClient, mint, WithFeeBasisPoints(100))
+	if err != nil {
+		t.Fatalf("GetCompletionCost() error = %s", err)
+	}
+	// 85 SOL moves the virtual SOL reserves to the 115 SOL they hold once complete, plus the 1% fee.
+	if sol != 85855412648 || tokens != 793100000000000 {
+		t.Fatalf("GetCompletionCost() = %d, %d, want %d, %d", sol, tokens, 85855412648, 793100000000000)
+	}
+	complete := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(0),
+		VirtualTokenReserves: big.NewInt(279900000000000),
+		VirtualSolReserves:   big.NewInt(115005359057),
+		Complete:             true,
+	}
+	if _, _, err := CompletionCost(complete, 100); !errors.Is(err, ErrBondingCurveComplete) {
+		t.Fatalf("CompletionCost() on a complete bonding curve error = %v, want %v", err, ErrBondingCurveComplete)
+	}
+}
+
+func TestFetchBondingCurve(t *testing.T) {
+	bondingCurve := solana.NewWallet().PublicKey()
+	short := solana.NewWallet().PublicKey()
+	missing := solana.NewWallet().PublicKey()
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		bondingCurve: bondingCurveAccountData(1073000000000000, 30000000000, 793100000000000, 0, 1000000000000000, false),
+		short:        make([]byte, 40),
+	}}
+	got, err := GetBondingCurveData(context.Background(), rpcClient, bondingCurve)
+	if err != nil {
+		t.Fatalf("GetBondingCurveData() error = %s", err)
+	}
+	want := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(793100000000000),
+		VirtualTokenReserves: big.NewInt(1073000000000000),
+		VirtualSolReserves:   big.NewInt(30000000000),
+		RealSolReserves:      big.NewInt(0),
+		TokenTotalSupply:     big.NewInt(1000000000000000),
+	}
+	if got.String() != want.String() {
+		t.Fatalf("GetBondingCurveData() = %s, want %s", got, want)
+	}
+	if _, err := fetchBondingCurve(context.Background(), rpcClient, short, rpc.CommitmentConfirmed); err == nil {
+		t.Fatal("fetchBondingCurve() of a short account, want error")
+	}
+	if _, err := fetchBondingCurve(context.Background(), rpcClient, missing, rpc.CommitmentConfirmed); !errors.Is(err, rpc.ErrNotFound) {
+		t.Fatalf("fetchBondingCurve() of a missing account error = %v, want %v", err, rpc.ErrNotFound)
+	}
+}
+
+func TestGetBondingCurveFromOptions(t *testing.T) {
+	bondingCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(793100000000000),
+		VirtualTokenReserves: big.NewInt(1073000000000000),
+		VirtualSolReserves:   big.NewInt(30000000000),
+	}
+	rpcClient := &mockRPCClient{}
+	got, err := getBondingCurve(context.Background(), rpcClient, solana.NewWallet().PublicKey(), newOptions([]Option{WithBondingCurve(bondingCurve)}))
+	if err != nil {
+		t.Fatalf("getBondingCurve() error = %s", err)
+	}
+	if got != bondingCurve || rpcClient.calls != 0 {
+		t.Fatalf("getBondingCurve() = %s after %d RPC calls, want %s without RPC call", got, rpcClient.calls, bondingCurve)
+	}
+	if _, err := getBondingCurve(context.Background(), rpcClient, solana.NewWallet().PublicKey(), newOptions([]Option{WithBondingCurve(&BondingCurveData{})})); err == nil {
+		t.Fatal("getBondingCurve() with an empty bonding curve, want error")
+	}
+}
+
+func TestDecodeBondingCurveComplete(t *testing.T) {
+	for _, complete := range []bool{false, true} {
+		bondingCurve, err := decodeBondingCurve(bondingCurveAccountData(279900000000000, 115005359057, 0, 85005359057, 1000000000000000, complete))
+		if err != nil {
+			t.Fatalf("decodeBondingCurve() error = %s", err)
+		}
+		if bondingCurve.Complete != complete {
+			t.Fatalf("decodeBondingCurve().Complete = %t, want %t", bondingCurve.Complete, complete)
+		}
+	}
+}
+
+func TestDecodeBondingCurveAccountDump(t *testing.T) {
+	// A completed bonding curve account, as returned by getAccountInfo, followed by 32 bytes of a newer layout.
+	data, err := base64.StdEncoding.DecodeString("F7f4N2DYrGAAmBJMkf4AANGD2sYaAAAAAAAAAAAAAADR17bKEwAAAACAxqR+jQMAAQECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeBondingCurve(data)
+	if err != nil {
+		t.Fatalf("decodeBondingCurve() error = %s", err)
+	}
+	want := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(0),
+		VirtualTokenReserves: big.NewInt(279900000000000),
+		VirtualSolReserves:   big.NewInt(115005359057),
+		RealSolReserves:      big.NewInt(85005359057),
+		TokenTotalSupply:     big.NewInt(1000000000000000),
+		Complete:             true,
+	}
+	if got.String() != want.String() {
+		t.Fatalf("decodeBondingCurve() = %s, want %s", got, want)
+	}
+}
+
+func TestDecodeBondingCurveDiscriminator(t *testing.T) {
+	data := bondingCurveAccountData(279900000000000, 115005359057, 0, 85005359057, 1000000000000000, false)
+	// Another account of the program, e.g. the global account, isn't a bonding curve.
+	copy(data, pump.GlobalDiscriminator[:])
+	if _, err := decodeBondingCurve(data); err == nil {
+		t.Fatal("decodeBondingCurve() of another account, want error")
+	}
+}
+
+func TestWaitForBondingCurve(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForBondingCurve(ctx, rpcClient, mint, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForBondingCurve() of a missing bonding curve error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if rpcClient.calls < 2 {
+		t.Fatalf("WaitForBondingCurve() fetched the bonding curve %d times, want it to retry", rpcClient.calls)
+	}
+	rpcClient.accounts[bondingCurveData.BondingCurve] = bondingCurveAccountData(1073000000000000, 30000000000, 793100000000000, 0, 1000000000000000, false)
+	got, err := WaitForBondingCurve(context.Background(), rpcClient, mint, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForBondingCurve() error = %s", err)
+	}
+	if got.VirtualSolReserves.Int64() != 30000000000 {
+		t.Fatalf("WaitForBondingCurve() = %s, want VirtualSolReserves=30000000000", got)
+	}
+}
+
+func TestGetBuyInstructionsMinTokens(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	user := solana.NewWallet().PublicKey()
+	ata, err := findAssociatedTokenAddress(user, mint, token.ProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bondingCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(793100000000000),
+		VirtualTokenReserves: big.NewInt(1073000000000000),
+		VirtualSolReserves:   big.NewInt(30000000000),
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{ata: {}}}
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    uint64
+		wantErr bool
+	}{
+		{"from slippage", nil, 33595527126955, false},
+		{"explicit", []Option{WithMinTokens(30000000000000)}, 30000000000000, false},
+		{"zero", []Option{WithMinTokens(0)}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithFeeBasisPoints(100)}, tt.opts...)
+			instructions, err := getBuyInstructions(context.Background(), rpcClient, mint, user, 1000000000, 200, bondingCurve, newOptions(opts))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuyInstructions() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			buy := instructions[len(instructions)-1].(*pump.Instruction)
+			if got := *buy.Impl.(pump.Buy).Amount; got != tt.want {
+				t.Fatalf("getBuyInstructions() amount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// BroadcastTransaction sends the signed transaction to all the clients concurrently, to raise the odds
+// it quickly reaches the leader. It returns the signature as soon as one client accepts the transaction,
+// and an error only if all of them reject it. A client answering that the transaction was already
+// processed counts as a success, since another client delivered it.
+func BroadcastTransaction(ctx context.Context, tx *solana.Transaction, opts rpc.TransactionOpts, clients ...RPCClient) (solana.Signature, error) {
+	if len(clients) == 0 {
+		return solana.Signature{}, errors.New("no RPC endpoint")
+	}
+	type result struct {
+		sig solana.Signature
+		err error
+	}
+	results := make(chan result, len(clients))
+	for _, client := range clients {
+		go func() {
+			sig, err := client.SendTransactionWithOpts(ctx, tx, opts)
+			if isAlreadyProcessed(err) {
+				sig, err = tx.Signatures[0], nil
+			}
+			results <- result{sig: sig, err: err}
+		}()
+	}
+	var errs []error
+	for range clients {
+		res := <-results
+		if res.err == nil {
+			return res.sig, nil
+		}
+		errs = append(errs, res.err)
+	}
+	return solana.Signature{}, fmt.Errorf("all %d RPC endpoints rejected the transaction: %w", len(clients), errors.Join(errs...))
+}
+
+// sendTransaction sends the transaction with the client, and the broadcast clients of the options if any.
+// The error carries the programs of the transaction, for MapProgramError.
+func sendTransaction(ctx context.Context, rpcClient RPCClient, tx *solana.Transaction, o *options) (solana.Signature, error) {
+	o.progress(StageSendingTransaction)
+	if len(o.broadcastClients) == 0 {
+		sig, err := rpcClient.SendTransactionWithOpts(ctx, tx, sendOpts(o))
+		return sig, withTransactionPrograms(err, tx)
+	}
+	clients := append([]RPCClient{rpcClient}, o.broadcastClients...)
+	sig, err := BroadcastTransaction(ctx, tx, sendOpts(o), clients...)
+	return sig, withTransactionPrograms(err, tx)
+}
+
+// isAlreadyProcessed returns true if the transaction was rejected because it already landed.
+func isAlreadyProcessed(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "already been processed")
+}
@@ -0,0 +1,40 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestBroadcastTransaction(t *testing.T) {
+	tx := &solana.Transaction{Signatures: []solana.Signature{{1}}}
+	rejected := errors.New("rate limited")
+	alreadyProcessed := errors.New("Transaction simulation failed: This transaction has already been processed")
+	tests := []struct {
+		name    string
+		errs    []error
+		wantErr bool
+	}{
+		{"one accepts", []error{rejected, nil}, false},
+		{"already processed", []error{rejected, alreadyProcessed}, false},
+		{"all reject", []error{rejected, rejected}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clients []RPCClient
+			for _, err := range tt.errs {
+				clients = append(clients, &mockRPCClient{err: err})
+			}
+			sig, err := BroadcastTransaction(context.Background(), tx, rpc.TransactionOpts{}, clients...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BroadcastTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && sig != tx.Signatures[0] {
+				t.Fatalf("BroadcastTransaction() = %s, want %s", sig, tx.Signatures[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// BurstBuy is one of the buys of BuildBurstBuyTransactions.
+type BurstBuy struct {
+	User               Signer
+	Mint               solana.PublicKey
+	Lamports           Lamports
+	SlippageBasisPoint uint
+}
+
+// BuildBurstBuyTransactions builds and signs the transactions of the buys, all against the same blockhash,
+// without sending them, so that they can be broadcast at once, e.g. for a snipe across several wallets.
+// Each buy is quoted against the current state of its bonding curve: the buys landing after others
+// of the same mint get fewer tokens, so their slippage must cover the ones before them.
+// The options apply to every buy, except the blockhash.
+func BuildBurstBuyTransactions(ctx context.Context, rpcClient RPCClient, buys []BurstBuy, blockhash solana.Hash, opts ...Option) ([]*solana.Transaction, error) {
+	if newOptions(opts).durableNonce != nil {
+		return nil, errors.New("can't build burst buys with a durable nonce, they share a blockhash")
+	}
+	opts = append(opts, WithBlockhash(blockhash))
+	txs := make([]*solana.Transaction, len(buys))
+	for i, buy := range buys {
+		tx, err := BuildBuyTransaction(ctx, rpcClient, buy.User, buy.Mint, buy.Lamports, buy.SlippageBasisPoint, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("can't build buy %d of %s: %w", i, buy.User.PublicKey(), err)
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
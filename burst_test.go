@@ -0,0 +1,42 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestBuildBurstBuyTransactions(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		bondingCurveData.BondingCurve: bondingCurveAccountData(1073000000000000, 30000000000, 793100000000000, 0, 1000000000000000, false),
+	}}
+	buys := make([]BurstBuy, 3)
+	for i := range buys {
+		buys[i] = BurstBuy{User: solana.NewWallet().PrivateKey, Mint: mint, Lamports: 100000000, SlippageBasisPoint: 1000}
+	}
+	blockhash := solana.Hash{42}
+	txs, err := BuildBurstBuyTransactions(context.Background(), rpcClient, buys, blockhash, WithFeeBasisPoints(100))
+	if err != nil {
+		t.Fatalf("BuildBurstBuyTransactions() error = %s", err)
+	}
+	if len(txs) != len(buys) {
+		t.Fatalf("BuildBurstBuyTransactions() returned %d transactions, want %d", len(txs), len(buys))
+	}
+	for i, tx := range txs {
+		if tx.Message.RecentBlockhash != blockhash {
+			t.Fatalf("transaction %d blockhash = %s, want %s", i, tx.Message.RecentBlockhash, blockhash)
+		}
+		if !tx.Message.AccountKeys[0].Equals(buys[i].User.PublicKey()) {
+			t.Fatalf("transaction %d payer = %s, want %s", i, tx.Message.AccountKeys[0], buys[i].User.PublicKey())
+		}
+		if err := tx.VerifySignatures(); err != nil {
+			t.Fatalf("transaction %d signatures: %s", i, err)
+		}
+	}
+}
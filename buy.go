@@ -2,26 +2,28 @@ package pumpdotfunsdk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/gagliardetto/solana-go"
-	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
-	cb "github.com/gagliardetto/solana-go/programs/compute-budget"
-	"github.com/gagliardetto/solana-go/programs/system"
-	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+	"golang.org/x/sync/errgroup"
 )
 
 // checks if the associated token account for the mint and our bot's public key exists.
-func shouldCreateAta(rpcClient *rpc.Client, ata solana.PublicKey) (bool, error) {
-	_, err := rpcClient.GetAccountInfo(context.TODO(), ata)
-	if err == nil {
-		return false, nil
+// Some RPC providers answer with a nil value rather than an error for an account that doesn't exist.
+func shouldCreateAta(ctx context.Context, rpcClient RPCClient, ata solana.PublicKey) (bool, error) {
+	out, err := rpcClient.GetAccountInfo(ctx, ata)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return true, nil
 	}
-	return true, nil
+	if err != nil {
+		return false, err
+	}
+	return out == nil || out.Value == nil, nil
 }
 
 // buyToken buys a token from the bonding curve.
@@ -29,109 +31,219 @@ func shouldCreateAta(rpcClient *rpc.Client, ata solana.PublicKey) (bool, error)
 // The mintAddr is the address of the mint of the token.
 // This function will send a transaction to the network to buy the token.
 // This function will return an error if the transaction fails.
+// The transaction isn't confirmed, unless WithFeeEscalation is used.
 func BuyToken(
-	rpcClient *rpc.Client,
+	ctx context.Context,
+	rpcClient RPCClient,
 	wsClient *ws.Client,
-	user solana.PrivateKey,
+	user Signer,
 	mint solana.PublicKey,
-	buyAmountLamports uint64,
+	buyAmountLamports Lamports,
 	slippageBasisPoint uint,
-) (string, error) {
-	// create priority fee instructions
-	culInst := cb.NewSetComputeUnitLimitInstruction(uint32(250000))
-	cupInst := cb.NewSetComputeUnitPriceInstruction(100000)
-	instructions := []solana.Instruction{
-		culInst.Build(),
-		cupInst.Build(),
+	opts ...Option,
+) (*TradeResult, error) {
+	o := newOptions(opts)
+	computeUnitPrice, buyInstructions, err := prepareBuy(ctx, rpcClient, user, mint, buyAmountLamports, slippageBasisPoint, o)
+	if err != nil {
+		return nil, err
+	}
+	// The associated token account creation, if any, comes before the buy instruction.
+	if o.separateAtaTransaction && len(buyInstructions) > 1 {
+		if err := sendAtaTransaction(ctx, rpcClient, wsClient, user, computeUnitPrice, buyInstructions[0], o); err != nil {
+			return nil, err
+		}
+		buyInstructions = buyInstructions[1:]
+	}
+	build := tradeTransactionBuilder(ctx, rpcClient, user, buyInstructions, o)
+	return sendTrade(ctx, rpcClient, wsClient, computeUnitPrice, buyMaxComputeUnitPrice(buyAmountLamports, o), build, o)
+}
+
+// BuildBuyTransaction returns the signed transaction BuyToken would send, without sending it.
+// Its signature, the first one, is known before sending it, so it can be persisted first to make the buy resumable:
+// after a crash, check the status of the signature, and send the same transaction again if it didn't land.
+// Sending it twice can't buy twice, and it can't land anymore once its blockhash expired.
+func BuildBuyTransaction(
+	ctx context.Context,
+	rpcClient RPCClient,
+	user Signer,
+	mint solana.PublicKey,
+	buyAmountLamports Lamports,
+	slippageBasisPoint uint,
+	opts ...Option,
+) (*solana.Transaction, error) {
+	o := newOptions(opts)
+	computeUnitPrice, buyInstructions, err := prepareBuy(ctx, rpcClient, user, mint, buyAmountLamports, slippageBasisPoint, o)
+	if err != nil {
+		return nil, err
+	}
+	if maxPrice := buyMaxComputeUnitPrice(buyAmountLamports, o); maxPrice > 0 {
+		computeUnitPrice = min(computeUnitPrice, maxPrice)
+	}
+	return tradeTransactionBuilder(ctx, rpcClient, user, buyInstructions, o)(computeUnitPrice)
+}
+
+// prepareBuy returns the compute unit price and the instructions of a buy, after checking the balance of the user
+// if the options require it.
+func prepareBuy(
+	ctx context.Context,
+	rpcClient RPCClient,
+	user Signer,
+	mint solana.PublicKey,
+	buyAmountLamports Lamports,
+	slippageBasisPoint uint,
+	o *options,
+) (uint64, []solana.Instruction, error) {
+	computeUnitPrice, err := getComputeUnitPrice(ctx, o, func() (uint64, error) {
+		return defaultBuyComputeUnitPrice, nil
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get compute unit price: %w", err)
+	}
+	if o.balanceCheck {
+		cost, err := estimateBuyCost(ctx, rpcClient, mint, user.PublicKey(), buyAmountLamports, computeUnitPrice, o)
+		if err != nil {
+			return 0, nil, fmt.Errorf("can't estimate buy cost: %w", err)
+		}
+		required := cost.Total
+		if o.feePayer != nil {
+			required = cost.BuyAmount + cost.AtaRent
+		}
+		if err := checkBalance(ctx, rpcClient, user.PublicKey(), required); err != nil {
+			return 0, nil, err
+		}
 	}
 	// get buy instructions
 	buyInstructions, err := getBuyInstructions(
+		ctx,
 		rpcClient,
 		mint,
 		user.PublicKey(),
-		buyAmountLamports,
+		uint64(buyAmountLamports),
 		slippageBasisPoint,
+		nil,
+		o,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to get buy instructions: %w", err)
+		return 0, nil, fmt.Errorf("failed to get buy instructions: %w", err)
 	}
-	instructions = append(instructions, buyInstructions...)
-	// get recent block hash
-	recent, err := rpcClient.GetLatestBlockhash(context.TODO(), rpc.CommitmentFinalized)
-	if err != nil {
-		return "", fmt.Errorf("error while getting recent block hash: %w", err)
+	return computeUnitPrice, buyInstructions, nil
+}
+
+// buyMaxComputeUnitPrice returns the highest compute unit price of a buy allowed by the max fee fraction
+// of the options, 0 if there is none.
+func buyMaxComputeUnitPrice(buyAmountLamports Lamports, o *options) uint64 {
+	if o.maxFeeFraction <= 0 {
+		return 0
 	}
-	// create new transaction
-	tx, err := solana.NewTransaction(
-		instructions,
-		recent.Value.Blockhash,
-		solana.TransactionPayer(user.PublicKey()),
-	)
-	if err != nil {
-		return "", fmt.Errorf("error while creating new transaction: %w", err)
-	}
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			if user.PublicKey().Equals(key) {
-				return &user
-			}
-			return nil
-		},
-	)
+	// A zero compute unit price would disable the cap.
+	return max(maxComputeUnitPrice(buyAmountLamports, o.maxFeeFraction), 1)
+}
+
+// sendAtaTransaction sends the instruction creating the associated token account of the user in its own transaction,
+// and waits for its confirmation.
+func sendAtaTransaction(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsClient *ws.Client,
+	user Signer,
+	computeUnitPrice uint64,
+	ataInstruction solana.Instruction,
+	o *options,
+) error {
+	instructions := append(computeBudgetInstructions(computeUnitPrice, o), ataInstruction)
+	tx, err := buildTransaction(ctx, rpcClient, instructions, o, user)
 	if err != nil {
-		return "", fmt.Errorf("can't sign transaction: %w", err)
+		return err
+	}
+	build := func() (*solana.Transaction, error) {
+		return buildTransaction(ctx, rpcClient, instructions, o, user)
 	}
-	// Send transaction:
-	sig, err := rpcClient.SendTransaction(context.TODO(), tx)
+	send := func(tx *solana.Transaction) (*Confirmation, error) {
+		return sendAndConfirmTransaction(ctx, rpcClient, wsClient, tx, o)
+	}
+	_, confirmation, err := sendWithBlockhashRetry(tx, build, send, o)
 	if err != nil {
-		return "", fmt.Errorf("can't send transaction: %w", err)
+		return fmt.Errorf("can't create associated token account: %w", MapProgramError(err))
 	}
-	return sig.String(), nil
+	o.logger.Info("created associated token account", "signature", confirmation.Signature)
+	return nil
+}
+
+// BuyInstructions returns the pump.fun instructions BuyToken would send, creating the associated token account
+// of the user if needed, without the compute budget instructions. It allows to assemble them with the instructions
+// of other programs in a transaction, leaving the compute budget, the signing and the sending to the caller.
+func BuyInstructions(
+	ctx context.Context,
+	rpcClient RPCClient,
+	user solana.PublicKey,
+	mint solana.PublicKey,
+	buyAmountLamports Lamports,
+	slippageBasisPoint uint,
+	opts ...Option,
+) ([]solana.Instruction, error) {
+	return getBuyInstructions(ctx, rpcClient, mint, user, uint64(buyAmountLamports), slippageBasisPoint, nil, newOptions(opts))
 }
 
+// getBuyInstructions returns the instructions to buy the token, creating the associated token account if needed.
+// If bondingCurve is nil, it is fetched from the network.
 func getBuyInstructions(
-	rpcClient *rpc.Client,
+	ctx context.Context,
+	rpcClient RPCClient,
 	mint solana.PublicKey,
 	user solana.PublicKey,
 	solAmount uint64,
 	slippageBasisPoint uint,
+	bondingCurve *BondingCurveData,
+	o *options,
 ) ([]solana.Instruction, error) {
-	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if o.maxSolCost > 0 {
+		solAmount = min(solAmount, uint64(o.maxSolCost))
+	}
+	if o.minTokens != nil && *o.minTokens == 0 {
+		return nil, errors.New("minimum tokens must be positive")
+	}
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bonding curve data: %w", err)
 	}
 	// NOTE: buy transaction for the token
 	var instructions []solana.Instruction
-	ata, _, err := solana.FindAssociatedTokenAddress(
-		user,
-		mint,
-	)
+	ata, err := findAssociatedTokenAddress(user, mint, o.getTokenProgram())
 	if err != nil {
-		return nil, fmt.Errorf("failed to derive associated token account: %w", err)
+		return nil, err
 	}
-	shouldCreateATA, err := shouldCreateAta(rpcClient, ata)
+	o.logger.Debug("derived buy addresses", "mint", mint, "bondingCurve", bondingCurveData.BondingCurve, "associatedBondingCurve", bondingCurveData.AssociatedBondingCurve, "ata", ata)
+	// Check the ATA and fetch the bonding curve concurrently, to save a round-trip.
+	shouldCreateATA, bondingCurve, err := checkAtaAndGetBondingCurve(ctx, rpcClient, ata, bondingCurveData.BondingCurve, bondingCurve, o)
 	if err != nil {
-		return nil, fmt.Errorf("can't check if we should create ATA: %w", err)
+		return nil, err
 	}
 	if shouldCreateATA {
-		ataInstr, err := associatedtokenaccount.NewCreateInstruction(user, user, mint).
-			ValidateAndBuild()
+		ataInstr, err := newCreateAtaInstruction(user, user, mint, o.getTokenProgram())
 		if err != nil {
 			return nil, fmt.Errorf("can't create associated token account: %w", err)
 		}
 		instructions = append(instructions, ataInstr)
 	}
 
-	bondingCurve, err := fetchBondingCurve(rpcClient, bondingCurveData.BondingCurve)
+	feeBasisPoints, err := getFeeBasisPoints(ctx, rpcClient, o)
 	if err != nil {
-		return nil, fmt.Errorf("can't fetch bonding curve: %w", err)
-	}
-	// We set 2% slippage.
-	percentage := convertSlippageBasisPointsToPercentage(slippageBasisPoint)
-	buy := calculateBuyQuote(solAmount, bondingCurve, percentage)
-	buyInstr := pump.NewBuyInstruction(
-		buy.Uint64(),
-		solAmount,
+		return nil, err
+	}
+	buy := calculateBuyQuote(solAmount, bondingCurve, slippageBasisPoint, feeBasisPoints)
+	if o.minTokens != nil {
+		buy.SetUint64(uint64(*o.minTokens))
+	}
+	o.logger.Debug("computed buy quote", "bondingCurve", bondingCurve, "solAmount", solAmount, "minTokens", buy, "feeBasisPoints", feeBasisPoints, "createAta", shouldCreateATA)
+	instructions = append(instructions, newBuyInstruction(buy.Uint64(), solAmount, mint, user, bondingCurveData, ata, o))
+	return instructions, nil
+}
+
+// newBuyInstruction returns the pump.fun instruction buying the amount of tokens of the mint for at most maxSolCost.
+func newBuyInstruction(amount uint64, maxSolCost uint64, mint solana.PublicKey, user solana.PublicKey, bondingCurveData *BondingCurvePublicKeys, ata solana.PublicKey, o *options) solana.Instruction {
+	return pump.NewBuyInstruction(
+		amount,
+		maxSolCost,
 		globalPumpFunAddress,
 		pumpFunFeeRecipient,
 		mint,
@@ -139,32 +251,94 @@ func getBuyInstructions(
 		bondingCurveData.AssociatedBondingCurve,
 		ata,
 		user,
-		system.ProgramID,
-		token.ProgramID,
-		solana.SysVarRentPubkey,
+		pumpFunSystemProgram,
+		o.getTokenProgram(),
+		pumpFunRentSysvar,
 		pumpFunEventAuthority,
 		pump.ProgramID,
-	)
-	buyInstruction := buyInstr.Build()
-	instructions = append(instructions, buyInstruction)
-	return instructions, nil
+	).Build()
 }
 
-func convertSlippageBasisPointsToPercentage(slippageBasisPoint uint) float64 {
-	return 1.0 - float64(slippageBasisPoint)/10e3
+// BuyAccounts returns the ordered accounts of the pump.fun buy instruction of the user for the mint, as BuyToken
+// would send it, e.g. to audit them before signing. It doesn't use the RPC.
+func BuyAccounts(user solana.PublicKey, mint solana.PublicKey, opts ...Option) ([]*solana.AccountMeta, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	ata, err := findAssociatedTokenAddress(user, mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	return newBuyInstruction(0, 0, mint, user, bondingCurveData, ata, o).Accounts(), nil
+}
+
+// checkAtaAndGetBondingCurve checks if the ATA should be created and gets the bonding curve, unless already known,
+// concurrently. The first error cancels the other call.
+func checkAtaAndGetBondingCurve(
+	ctx context.Context,
+	rpcClient RPCClient,
+	ata solana.PublicKey,
+	bondingCurvePubKey solana.PublicKey,
+	bondingCurve *BondingCurveData,
+	o *options,
+) (bool, *BondingCurveData, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	var shouldCreateATA bool
+	g.Go(func() error {
+		var err error
+		shouldCreateATA, err = shouldCreateAta(ctx, rpcClient, ata)
+		if err != nil {
+			return fmt.Errorf("can't check if we should create ATA: %w", err)
+		}
+		return nil
+	})
+	if bondingCurve == nil {
+		g.Go(func() error {
+			var err error
+			bondingCurve, err = getBondingCurve(ctx, rpcClient, bondingCurvePubKey, o)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return false, nil, err
+	}
+	return shouldCreateATA, bondingCurve, nil
+}
+
+// applySlippage returns the amount minus the slippage, rounded down, so that it never exceeds what the program computes.
+func applySlippage(amount *big.Int, slippageBasisPoint uint) *big.Int {
+	if slippageBasisPoint >= 10000 {
+		return new(big.Int)
+	}
+	out := new(big.Int).Mul(amount, big.NewInt(int64(10000-slippageBasisPoint)))
+	return out.Div(out, big.NewInt(10000))
 }
 
-// calculateBuyQuote calculates how many tokens can be purchased given a specific amount of SOL, bonding curve data, and percentage.
+// addSlippage returns the amount plus the slippage, rounded up, e.g. the most SOL a buy allows to spend over its cost.
+func addSlippage(amount *big.Int, slippageBasisPoint uint) *big.Int {
+	out := new(big.Int).Mul(amount, big.NewInt(int64(10000+slippageBasisPoint)))
+	out.Add(out, big.NewInt(9999))
+	return out.Div(out, big.NewInt(10000))
+}
+
+// calculateBuyQuote calculates how many tokens can be purchased given a specific amount of SOL, bonding curve data, and slippage.
 // solAmount is the amount of sol that you want to buy
 // bondingCurve is the BondingCurveData, that includes the real, virtual token/sol reserves, in order to calculate the price.
-// percentage is what you want to use to set the slippage. For 2% slippage, you want to set the percentage to 0.98.
+// slippageBasisPoint is the slippage, 200 means 2%.
+// feeBasisPoints is the fee pump.fun charges on top of the SOL spent on the curve, 100 means 1%.
 func calculateBuyQuote(
 	solAmount uint64,
 	bondingCurve *BondingCurveData,
-	percentage float64,
+	slippageBasisPoint uint,
+	feeBasisPoints uint64,
 ) *big.Int {
-	// Convert solAmount to *big.Int
-	solAmountBig := big.NewInt(int64(solAmount))
+	// Convert solAmount to *big.Int, without the pump.fun fee.
+	// The fee is charged on top of the SOL spent on the curve, so that solAmount pays for both.
+	solAmountBig := new(big.Int).SetUint64(solAmount)
+	solAmountBig.Mul(solAmountBig, big.NewInt(10000))
+	solAmountBig.Div(solAmountBig, new(big.Int).SetUint64(10000+feeBasisPoints))
 
 	// Clone bonding curve data to avoid mutations
 	virtualSolReserves := new(big.Int).Set(bondingCurve.VirtualSolReserves)
@@ -178,14 +352,38 @@ func calculateBuyQuote(
 	// Calculate the tokens to buy
 	tokensToBuy := new(big.Int).Sub(virtualTokenReserves, newVirtualTokenReserves)
 
-	// Apply the percentage reduction (e.g., 95% or 0.95)
-	// Convert the percentage to a multiplier (0.95) and apply to tokensToBuy
-	percentageMultiplier := big.NewFloat(percentage)
-	tokensToBuyFloat := new(big.Float).SetInt(tokensToBuy)
-	finalTokens := new(big.Float).Mul(tokensToBuyFloat, percentageMultiplier)
+	return applySlippage(tokensToBuy, slippageBasisPoint)
+}
 
-	// Convert the result back to *big.Int
-	finalTokensBig, _ := finalTokens.Int(nil)
+// chargedBuyCost returns the SOL the program charges to buy the amount of tokens, computed the way it does:
+// the cost rounded down plus a lamport, and the fee on it rounded down. calculateBuyCost is at most a lamport over it.
+func chargedBuyCost(tokenAmount uint64, bondingCurve *BondingCurveData, feeBasisPoints uint64) (*big.Int, error) {
+	amount := new(big.Int).SetUint64(tokenAmount)
+	if amount.Cmp(bondingCurve.RealTokenReserves) > 0 {
+		return nil, fmt.Errorf("can't buy %s tokens, only %s are left in the bonding curve", amount, bondingCurve.RealTokenReserves)
+	}
+	sol := new(big.Int).Mul(bondingCurve.VirtualSolReserves, amount)
+	sol.Div(sol, new(big.Int).Sub(bondingCurve.VirtualTokenReserves, amount))
+	sol.Add(sol, big.NewInt(1))
+	fee := new(big.Int).Mul(sol, new(big.Int).SetUint64(feeBasisPoints))
+	fee.Div(fee, big.NewInt(10000))
+	return sol.Add(sol, fee), nil
+}
 
-	return finalTokensBig
+// calculateBuyCost calculates how many SOL are needed to buy a specific amount of tokens, given the bonding curve data,
+// including the pump.fun fee. It is the inverse of calculateBuyQuote without slippage, rounded up so that the SOL is always enough.
+func calculateBuyCost(tokenAmount uint64, bondingCurve *BondingCurveData, feeBasisPoints uint64) (*big.Int, error) {
+	amount := new(big.Int).SetUint64(tokenAmount)
+	if amount.Cmp(bondingCurve.RealTokenReserves) > 0 {
+		return nil, fmt.Errorf("can't buy %s tokens, only %s are left in the bonding curve", amount, bondingCurve.RealTokenReserves)
+	}
+	// sol = virtualSolReserves * amount / (virtualTokenReserves - amount)
+	x := new(big.Int).Mul(bondingCurve.VirtualSolReserves, amount)
+	y := new(big.Int).Sub(bondingCurve.VirtualTokenReserves, amount)
+	sol := new(big.Int).Div(x, y)
+	sol.Add(sol, big.NewInt(1))
+	// Add the pump.fun fee, rounded up.
+	sol.Mul(sol, new(big.Int).SetUint64(10000+feeBasisPoints))
+	sol.Add(sol, big.NewInt(9999))
+	return sol.Div(sol, big.NewInt(10000)), nil
 }
@@ -19,32 +19,52 @@ type TestConfig struct {
 	mint       solana.PublicKey
 }
 
-func GetTestConfig() TestConfig {
+// GetTestConfig returns the devnet configuration of the integration tests.
+// The test is skipped if TEST_MINT isn't set. Without TEST_PRIVATE_KEY, a fresh wallet is funded by an airdrop.
+func GetTestConfig(t *testing.T) TestConfig {
+	mint := os.Getenv("TEST_MINT")
+	if mint == "" {
+		t.Skip("TEST_MINT not set")
+	}
 	testConfig := TestConfig{}
 	testConfig.rpcClient = rpc.New(rpc.DevNet_RPC)
 	wsClient, err := ws.Connect(context.Background(), rpc.DevNet_WS)
 	if err != nil {
-		panic(err)
+		t.Fatalf("can't connect to websocket: %s", err)
 	}
 	testConfig.wsClient = wsClient
-	testConfig.PrivateKey = solana.MustPrivateKeyFromBase58(os.Getenv("TEST_PRIVATE_KEY"))
-	testConfig.mint = solana.MustPublicKeyFromBase58(os.Getenv("TEST_MINT"))
+	testConfig.mint = solana.MustPublicKeyFromBase58(mint)
+	if privateKey := os.Getenv("TEST_PRIVATE_KEY"); privateKey != "" {
+		testConfig.PrivateKey = solana.MustPrivateKeyFromBase58(privateKey)
+		return testConfig
+	}
+	testConfig.PrivateKey = solana.NewWallet().PrivateKey
+	_, err = pumpdotfunsdk.RequestDevnetAirdrop(
+		context.Background(),
+		testConfig.rpcClient,
+		testConfig.PrivateKey.PublicKey(),
+		pumpdotfunsdk.SolToLamports(1),
+	)
+	if err != nil {
+		t.Fatalf("can't fund test wallet: %s", err)
+	}
 	return testConfig
 }
 
 func TestBuyToken(t *testing.T) {
-	testConfig := GetTestConfig()
+	testConfig := GetTestConfig(t)
 	pumpdotfunsdk.SetDevnetMode()
-	sig, err := pumpdotfunsdk.BuyToken(
+	res, err := pumpdotfunsdk.BuyToken(
+		context.Background(),
 		testConfig.rpcClient,
 		testConfig.wsClient,
 		testConfig.PrivateKey,
 		testConfig.mint,
-		10000,
+		pumpdotfunsdk.SolToLamports(0.00001),
 		100,
 	)
 	if err != nil {
 		t.Fatalf("can't buy token: %s", err)
 	}
-	t.Logf("buy token signature: %s", sig)
+	t.Logf("buy token signature: %s", res.Signature)
 }
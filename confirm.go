@@ -0,0 +1,175 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// confirmPollInterval is the interval between two signature status checks,
+// when confirming a transaction without the websocket.
+const confirmPollInterval = 500 * time.Millisecond
+
+// ConfirmationTimeoutError is returned when a transaction was sent, but wasn't confirmed in time.
+// The transaction may still land, so check its signature before retrying.
+type ConfirmationTimeoutError struct {
+	Signature solana.Signature
+	Timeout   time.Duration
+}
+
+func (e *ConfirmationTimeoutError) Error() string {
+	return fmt.Sprintf("transaction %s not confirmed after %s", e.Signature, e.Timeout)
+}
+
+// errSubscription is returned when the websocket subscription fails, and the confirmation should fall back to polling.
+var errSubscription = errors.New("signature subscription failed")
+
+// Confirmation is the outcome of a transaction that landed.
+type Confirmation struct {
+	Signature solana.Signature
+	// Slot is the slot the transaction landed in.
+	Slot uint64
+	// Status is the commitment the transaction reached.
+	Status rpc.ConfirmationStatusType
+	// Err is the error the transaction reverted with, nil if it succeeded.
+	Err error
+}
+
+// SendAndConfirm sends the transaction, and waits for its confirmation with the commitment of the options,
+// for at most the timeout, or the confirm timeout of the options if 0. A transaction that landed but reverted
+// isn't an error: the Err of the confirmation holds the reason, mapped by MapProgramError.
+func SendAndConfirm(ctx context.Context, rpcClient RPCClient, wsClient *ws.Client, tx *solana.Transaction, timeout time.Duration, opts ...Option) (*Confirmation, error) {
+	o := newOptions(opts)
+	if timeout > 0 {
+		o.confirmTimeout = timeout
+	}
+	sig, err := sendTransaction(ctx, rpcClient, tx, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't send transaction: %w", MapProgramError(err))
+	}
+	confirmation, err := confirmTransaction(ctx, rpcClient, wsClient, sig, o)
+	if err != nil {
+		return nil, err
+	}
+	confirmation.Err = MapTransactionError(confirmation.Err, tx)
+	return confirmation, nil
+}
+
+// sendAndConfirmTransaction sends the transaction, and waits for its confirmation
+// with the commitment and timeout of the options. It returns the error of the transaction if it reverted.
+func sendAndConfirmTransaction(ctx context.Context, rpcClient RPCClient, wsClient *ws.Client, tx *solana.Transaction, o *options) (*Confirmation, error) {
+	sig, err := sendTransaction(ctx, rpcClient, tx, o)
+	if err != nil {
+		return nil, err
+	}
+	confirmation, err := confirmTransaction(ctx, rpcClient, wsClient, sig, o)
+	if err != nil {
+		return nil, err
+	}
+	return confirmation, withTransactionPrograms(confirmation.Err, tx)
+}
+
+// waitForConfirmation waits for the confirmation of the transaction, and returns its error if it reverted.
+func waitForConfirmation(ctx context.Context, rpcClient RPCClient, wsClient *ws.Client, sig solana.Signature, o *options) error {
+	confirmation, err := confirmTransaction(ctx, rpcClient, wsClient, sig, o)
+	if err != nil {
+		return err
+	}
+	return confirmation.Err
+}
+
+// confirmTransaction waits for the confirmation of the transaction through the websocket,
+// falling back to polling its status over RPC if the websocket is nil or fails.
+func confirmTransaction(ctx context.Context, rpcClient RPCClient, wsClient *ws.Client, sig solana.Signature, o *options) (*Confirmation, error) {
+	o.progress(StageAwaitingConfirmation)
+	confirmCtx, cancel := context.WithTimeout(ctx, o.confirmTimeout)
+	defer cancel()
+	var confirmation *Confirmation
+	err := errSubscription
+	if wsClient != nil {
+		confirmation, err = waitForConfirmationWs(confirmCtx, wsClient, sig, o.confirmCommitment)
+	}
+	if errors.Is(err, errSubscription) {
+		confirmation, err = pollForConfirmation(confirmCtx, rpcClient, sig, o.confirmCommitment)
+	}
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, &ConfirmationTimeoutError{Signature: sig, Timeout: o.confirmTimeout}
+	}
+	return confirmation, err
+}
+
+func waitForConfirmationWs(ctx context.Context, wsClient *ws.Client, sig solana.Signature, commitment rpc.CommitmentType) (*Confirmation, error) {
+	sub, err := wsClient.SignatureSubscribe(sig, commitment)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errSubscription, err)
+	}
+	defer sub.Unsubscribe()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res, ok := <-sub.Response():
+		if !ok {
+			return nil, errSubscription
+		}
+		confirmation := &Confirmation{Signature: sig, Slot: res.Context.Slot, Status: commitmentStatus(commitment)}
+		if res.Value.Err != nil {
+			confirmation.Err = &transactionError{err: res.Value.Err}
+		}
+		return confirmation, nil
+	case err := <-sub.Err():
+		return nil, fmt.Errorf("%w: %w", errSubscription, err)
+	}
+}
+
+func pollForConfirmation(ctx context.Context, rpcClient RPCClient, sig solana.Signature, commitment rpc.CommitmentType) (*Confirmation, error) {
+	ticker := time.NewTicker(confirmPollInterval)
+	defer ticker.Stop()
+	for {
+		out, err := rpcClient.GetSignatureStatuses(ctx, false, sig)
+		if err == nil && len(out.Value) == 1 && out.Value[0] != nil {
+			status := out.Value[0]
+			confirmation := &Confirmation{Signature: sig, Slot: status.Slot, Status: status.ConfirmationStatus}
+			if status.Err != nil {
+				confirmation.Err = &transactionError{err: status.Err}
+				return confirmation, nil
+			}
+			if commitmentReached(status.ConfirmationStatus, commitment) {
+				return confirmation, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// commitmentStatus returns the status of a transaction that reached the commitment.
+func commitmentStatus(commitment rpc.CommitmentType) rpc.ConfirmationStatusType {
+	switch commitment {
+	case rpc.CommitmentProcessed:
+		return rpc.ConfirmationStatusProcessed
+	case rpc.CommitmentConfirmed:
+		return rpc.ConfirmationStatusConfirmed
+	default:
+		return rpc.ConfirmationStatusFinalized
+	}
+}
+
+// commitmentReached returns true if a transaction with the status has reached the commitment.
+func commitmentReached(status rpc.ConfirmationStatusType, commitment rpc.CommitmentType) bool {
+	switch commitment {
+	case rpc.CommitmentProcessed:
+		return status != ""
+	case rpc.CommitmentConfirmed:
+		return status == rpc.ConfirmationStatusConfirmed || status == rpc.ConfirmationStatusFinalized
+	default:
+		return status == rpc.ConfirmationStatusFinalized
+	}
+}
@@ -0,0 +1,76 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestSendAndConfirm(t *testing.T) {
+	user := solana.NewWallet().PrivateKey
+	failedErr := map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": 6003}}}
+	tests := []struct {
+		name       string
+		status     *rpc.SignatureStatusesResult
+		want       *Confirmation
+		wantErr    error
+		wantRevert error
+	}{
+		{
+			name:   "landed",
+			status: &rpc.SignatureStatusesResult{Slot: 123, ConfirmationStatus: rpc.ConfirmationStatusConfirmed},
+			want:   &Confirmation{Slot: 123, Status: rpc.ConfirmationStatusConfirmed},
+		},
+		{
+			name:       "reverted",
+			status:     &rpc.SignatureStatusesResult{Slot: 124, ConfirmationStatus: rpc.ConfirmationStatusProcessed, Err: failedErr},
+			want:       &Confirmation{Slot: 124, Status: rpc.ConfirmationStatusProcessed},
+			wantRevert: ErrSlippageExceeded,
+		},
+		{
+			name:    "not confirmed in time",
+			status:  &rpc.SignatureStatusesResult{Slot: 125, ConfirmationStatus: rpc.ConfirmationStatusProcessed},
+			wantErr: &ConfirmationTimeoutError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient := &mockRPCClient{statuses: map[solana.Signature]*rpc.SignatureStatusesResult{}}
+			o := newOptions([]Option{WithBlockhash(solana.Hash{42})})
+			// The pump.fun instruction is the instruction 2 the transaction fails at.
+			instructions := []solana.Instruction{
+				system.NewTransferInstruction(1, user.PublicKey(), user.PublicKey()).Build(),
+				system.NewTransferInstruction(2, user.PublicKey(), user.PublicKey()).Build(),
+				solana.NewInstruction(pump.ProgramID, solana.AccountMetaSlice{solana.Meta(user.PublicKey()).WRITE().SIGNER()}, nil),
+			}
+			tx, err := buildTransaction(context.Background(), rpcClient, instructions, o, user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rpcClient.statuses[tx.Signatures[0]] = tt.status
+			got, err := SendAndConfirm(context.Background(), rpcClient, nil, tx, 10*time.Millisecond, WithConfirmCommitment(rpc.CommitmentConfirmed))
+			if tt.wantErr != nil {
+				var timeoutErr *ConfirmationTimeoutError
+				if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 10*time.Millisecond {
+					t.Fatalf("SendAndConfirm() error = %v, want a timeout after 10ms", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendAndConfirm() error = %s", err)
+			}
+			if got.Signature != tx.Signatures[0] || got.Slot != tt.want.Slot || got.Status != tt.want.Status {
+				t.Fatalf("SendAndConfirm() = %+v, want %+v", got, tt.want)
+			}
+			if !errors.Is(got.Err, tt.wantRevert) || (tt.wantRevert == nil) != (got.Err == nil) {
+				t.Fatalf("SendAndConfirm() Err = %v, want %v", got.Err, tt.wantRevert)
+			}
+		})
+	}
+}
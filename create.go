@@ -8,22 +8,21 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	// General solana packages.
 	"github.com/gagliardetto/solana-go"
-	"github.com/gagliardetto/solana-go/rpc"
-	confirm "github.com/gagliardetto/solana-go/rpc/sendAndConfirmTransaction"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 
 	// This package interacts with the Compute Budget program, allowing
 	// to easily get instruction to set compute budget limit/price for example.
 	cb "github.com/gagliardetto/solana-go/programs/compute-budget"
-	// This package interacts with the Solana system program, allowing
-	// to transfer solana for example.
-	"github.com/gagliardetto/solana-go/programs/system"
 	// This package interacts with the Token program, allowing
 	// to create a token for example.
-	"github.com/gagliardetto/solana-go/programs/token"
 	// This package interacts with the Associated Token Account program
 	// allowing to create/close an associated token account for example.
 	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
@@ -32,37 +31,20 @@ import (
 	"github.com/zzispp/pumpdotfun-go-sdk/pump"
 )
 
-// Contains commonly used addresses with the pump.fun program, that are not present
-// in the generated code, from its IDL file.
-var (
-	// Global account address for pump.fun
-	globalPumpFunAddress = solana.MustPublicKeyFromBase58("4wTV1YmiEkRvAtNtsSGPtUrqRYQMe5SKy2uB4Jjaxnjf")
-	// Pump.fun mint authority
-	pumpFunMintAuthority = solana.MustPublicKeyFromBase58("TSLvdd1pWpHVjahSpsvCXUbgwsL3JAcvokwaKt1eokM")
-	// Pump.fun event authority
-	pumpFunEventAuthority = solana.MustPublicKeyFromBase58("Ce6TQqeHC9p8KetsN6JsjHK7UTZk7nasjjnr7XxXp9F1")
-	// Pump.fun fee recipient
-	pumpFunFeeRecipient = solana.MustPublicKeyFromBase58("CebN5WGQ4jvEPvsVU4EoHEpgzq1VV7AbicfhtW4xC9iM")
-)
-
-// SetDevnetMode sets the pump.fun program addresses to the devnet addresses.
-// It is important to call this function if you are using the devnet.
-func SetDevnetMode() {
-	// This is the address you want to use as pump.fun fee recipient on devnet, otherwise, it
-	// will not work, as the official pump.fun fee recipient account is not initialized on devnet.
-	// I know, using global variables is ugly, but passing this address around everywhere
-	// (in BuyToken / SellToken), while it's actually a constant on mainnet is even uglier,
-	// considering that there is no other difference.
-	pumpFunFeeRecipient = solana.MustPublicKeyFromBase58("68yFSZxzLWJXkxxRGydZ63C6mHx1NLEDWmwN9Lb5yySg")
-}
-
 type BondingCurvePublicKeys struct {
 	BondingCurve           solana.PublicKey
 	AssociatedBondingCurve solana.PublicKey
 }
 
+// bondingCurvePublicKeysCache caches the *BondingCurvePublicKeys of the mints, as deriving them is
+// deterministic but CPU intensive. It is safe for concurrent use.
+var bondingCurvePublicKeysCache sync.Map
+
 // getBondingCurveAndAssociatedBondingCurve returns the bonding curve and associated bonding curve, in a structured format.
 func getBondingCurveAndAssociatedBondingCurve(mint solana.PublicKey) (*BondingCurvePublicKeys, error) {
+	if cached, ok := bondingCurvePublicKeysCache.Load(mint); ok {
+		return cached.(*BondingCurvePublicKeys), nil
+	}
 	// Derive bonding curve address.
 	// define the seeds used to derive the PDA
 	// getProgramDerivedAddress equivalent.
@@ -82,45 +64,148 @@ func getBondingCurveAndAssociatedBondingCurve(mint solana.PublicKey) (*BondingCu
 	if err != nil {
 		return nil, fmt.Errorf("failed to derive associated bonding curve address: %w", err)
 	}
-	return &BondingCurvePublicKeys{
+	publicKeys := &BondingCurvePublicKeys{
 		BondingCurve:           bondingCurve,
 		AssociatedBondingCurve: associatedBondingCurve,
-	}, nil
+	}
+	bondingCurvePublicKeysCache.Store(mint, publicKeys)
+	return publicKeys, nil
 }
 
-func getComputUnitPriceInstr(rpcClient *rpc.Client, user solana.PrivateKey) (*cb.SetComputeUnitPrice, error) {
-	// create priority fee instructions
-	out, err := rpcClient.GetRecentPrioritizationFees(context.TODO(), solana.PublicKeySlice{user.PublicKey(), pump.ProgramID, pumpFunMintAuthority, globalPumpFunAddress, solana.TokenMetadataProgramID, system.ProgramID, token.ProgramID, associatedtokenaccount.ProgramID, solana.SysVarRentPubkey, pumpFunEventAuthority})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get recent prioritization fees: %w", err)
+// Limits enforced by pump.fun and the token metadata program on the token metadata.
+const (
+	maxNameLength   = 32
+	maxSymbolLength = 10
+	maxUriLength    = 200
+)
+
+// validateTokenMetadata checks the name, symbol and uri of a token before it gets created,
+// as pump.fun would otherwise only reject them on-chain, after the fees have been paid.
+func validateTokenMetadata(name string, symbol string, uri string) error {
+	if err := validateNameAndSymbol(name, symbol); err != nil {
+		return err
+	}
+	if uri == "" {
+		return fmt.Errorf("metadata uri is required")
+	}
+	if len(uri) > maxUriLength {
+		return fmt.Errorf("metadata uri is %d bytes long, maximum is %d", len(uri), maxUriLength)
+	}
+	return nil
+}
+
+// Handles accepted in place of the Twitter and Telegram links.
+var (
+	twitterHandleRegexp  = regexp.MustCompile(`^@?([A-Za-z0-9_]{1,15})$`)
+	telegramHandleRegexp = regexp.MustCompile(`^@?([A-Za-z0-9_]{5,32})$`)
+)
+
+// normalizeSocialLinks turns the Twitter and Telegram handles into links, adds https:// to the links without scheme,
+// and checks they are well-formed, as pump.fun displays them as is.
+func normalizeSocialLinks(create *CreateTokenMetadataRequest) error {
+	var err error
+	if create.Twitter, err = normalizeLink("twitter", create.Twitter, twitterHandleRegexp, "https://x.com/"); err != nil {
+		return err
+	}
+	if create.Telegram, err = normalizeLink("telegram", create.Telegram, telegramHandleRegexp, "https://t.me/"); err != nil {
+		return err
+	}
+	if create.Website, err = normalizeLink("website", create.Website, nil, ""); err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizeLink returns the link, expanding it after the prefix if it matches the handle regexp.
+func normalizeLink(name string, link string, handleRegexp *regexp.Regexp, prefix string) (string, error) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return "", nil
+	}
+	if handleRegexp != nil {
+		if match := handleRegexp.FindStringSubmatch(link); match != nil {
+			return prefix + match[1], nil
+		}
+	}
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
 	}
-	var median uint64
-	length := uint64(len(out))
-	for _, fee := range out {
-		median = fee.PrioritizationFee
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || !strings.Contains(u.Host, ".") || strings.ContainsAny(link, " \t\n") {
+		return "", fmt.Errorf("%s link %q is invalid", name, link)
 	}
-	median /= length
-	cupInst := cb.NewSetComputeUnitPriceInstruction(median)
-	return cupInst, nil
+	return link, nil
 }
 
-func CreateToken(rpcClient *rpc.Client, wsClient *ws.Client, user solana.PrivateKey, mint *solana.Wallet, name string, symbol string, uri string, buyAmountLamports uint64, slippageBasisPoint uint) (string, error) {
-	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint.PublicKey())
+func validateNameAndSymbol(name string, symbol string) error {
+	if name == "" {
+		return fmt.Errorf("token name is required")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("token name is %d bytes long, maximum is %d", len(name), maxNameLength)
+	}
+	if symbol == "" {
+		return fmt.Errorf("token symbol is required")
+	}
+	if len(symbol) > maxSymbolLength {
+		return fmt.Errorf("token symbol is %d bytes long, maximum is %d", len(symbol), maxSymbolLength)
+	}
+	return nil
+}
+
+// CreateResult holds everything about a newly created token.
+type CreateResult struct {
+	Signature solana.Signature
+	// Slot is the slot the create transaction landed in, 0 when using WithDryRun.
+	Slot         uint64
+	Mint         solana.PublicKey
+	BondingCurve solana.PublicKey
+	MetadataUri  string
+	// Transaction is the signed transaction, only set when using WithDryRun.
+	Transaction *solana.Transaction
+	// InitialBuy is the buy of the creator in the create transaction, nil if there is none.
+	InitialBuy *InitialBuyFill
+}
+
+// InitialBuyFill is the fill of the initial buy of CreateToken, computed from the initial bonding curve.
+// Nothing can trade before the creator in the create transaction, so the buy gets exactly the tokens it asks for.
+type InitialBuyFill struct {
+	// Tokens is the amount of tokens bought, the minimum computed from the slippage.
+	Tokens TokenAmount
+	// SolCost is the SOL spent on them, including the pump.fun fee.
+	SolCost Lamports
+	// MaxSolCost is the most SOL the buy instruction allows to spend.
+	MaxSolCost Lamports
+}
+
+// CreateToken creates a new pump.fun token, optionally buying some of it in the same transaction.
+// This function will send a transaction to the network and wait for its confirmation,
+// unless WithDryRun is used. The mint signs the transaction through the Signer interface, so its private key
+// can stay in an HSM; a solana.PrivateKey, e.g. the PrivateKey of solana.NewWallet(), can be passed as is.
+func CreateToken(ctx context.Context, rpcClient RPCClient, wsClient *ws.Client, user Signer, mint Signer, name string, symbol string, uri string, buyAmountLamports Lamports, slippageBasisPoint uint, opts ...Option) (*CreateResult, error) {
+	o := newOptions(opts)
+	if err := validateTokenMetadata(name, symbol, uri); err != nil {
+		return nil, fmt.Errorf("invalid token metadata: %w", err)
+	}
+	bondingCurveData, err := getBondingCurvePublicKeys(mint.PublicKey(), o)
 	if err != nil {
-		return "", fmt.Errorf("failed to get bonding curve and associated bonding curve: %w", err)
+		return nil, fmt.Errorf("failed to get bonding curve and associated bonding curve: %w", err)
 	}
 	// Get token metadata address
-	metadata, _, err := solana.FindTokenMetadataAddress(mint.PublicKey())
+	metadata, err := findTokenMetadataAddress(mint.PublicKey())
 	if err != nil {
-		return "", fmt.Errorf("can't find token metadata address: %w", err)
+		return nil, fmt.Errorf("can't find token metadata address: %w", err)
 	}
 
 	// Default pump.fun compute limit is 250k, so we set the same here.
-	culInst := cb.NewSetComputeUnitLimitInstruction(uint32(250000))
-	cupInst, err := getComputUnitPriceInstr(rpcClient, user)
+	culInst := cb.NewSetComputeUnitLimitInstruction(computeUnitLimit)
+	computeUnitPrice, err := getComputeUnitPrice(ctx, o, func() (uint64, error) {
+		return getRecentComputeUnitPrice(ctx, rpcClient, user.PublicKey(), o)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to get compute unit price instructions: %w", err)
+		return nil, fmt.Errorf("failed to get compute unit price: %w", err)
 	}
+	cupInst := cb.NewSetComputeUnitPriceInstruction(computeUnitPrice)
 	// Create the pump fun instruction
 	instr := pump.NewCreateInstruction(
 		name,
@@ -131,68 +216,200 @@ func CreateToken(rpcClient *rpc.Client, wsClient *ws.Client, user solana.Private
 		bondingCurveData.BondingCurve,
 		bondingCurveData.AssociatedBondingCurve,
 		globalPumpFunAddress,
-		solana.TokenMetadataProgramID,
+		pumpFunTokenMetadataProgram,
 		metadata,
 		user.PublicKey(),
-		system.ProgramID,
-		token.ProgramID,
+		pumpFunSystemProgram,
+		o.getTokenProgram(),
 		associatedtokenaccount.ProgramID,
-		solana.SysVarRentPubkey,
+		pumpFunRentSysvar,
 		pumpFunEventAuthority,
 		pump.ProgramID,
 	)
 	instruction := instr.Build()
-	// get recent block hash
-	recent, err := rpcClient.GetLatestBlockhash(context.TODO(), rpc.CommitmentFinalized)
-	if err != nil {
-		return "", fmt.Errorf("error while getting recent block hash: %w", err)
-	}
 	instructions := []solana.Instruction{
 		culInst.Build(),
 		cupInst.Build(),
 		instruction,
 	}
 	// get buy instructions
-	if buyAmountLamports > 0 {
-		buyInstructions, err := getBuyInstructions(rpcClient, mint.PublicKey(), user.PublicKey(), buyAmountLamports, slippageBasisPoint)
+	var initialBuy *InitialBuyFill
+	if buyAmountLamports > 0 || o.initialBuyTokens > 0 || o.initialBuyPercentage > 0 {
+		global, err := getGlobal(ctx, rpcClient)
+		if err != nil {
+			return nil, fmt.Errorf("can't fetch global account: %w", err)
+		}
+		// The bonding curve doesn't exist yet, so we buy from the initial one.
+		bondingCurve := initialBondingCurve(global)
+		feeBasisPoints := global.FeeBasisPoints
+		if o.feeBasisPoints != nil {
+			feeBasisPoints = *o.feeBasisPoints
+		}
+		tokenAmount, err := initialBuyTokens(global, bondingCurve, o)
+		if err != nil {
+			return nil, fmt.Errorf("can't compute initial buy: %w", err)
+		}
+		var buyInstructions []solana.Instruction
+		if tokenAmount > 0 {
+			buyInstructions, err = initialBuyInstructions(mint.PublicKey(), user.PublicKey(), tokenAmount, bondingCurve, feeBasisPoints, slippageBasisPoint, o)
+		} else {
+			buyAmountLamports, err = initialBuyLamports(bondingCurve, feeBasisPoints, buyAmountLamports, o)
+			if err != nil {
+				return nil, fmt.Errorf("can't compute initial buy: %w", err)
+			}
+			buyInstructions, err = getBuyInstructions(ctx, rpcClient, mint.PublicKey(), user.PublicKey(), uint64(buyAmountLamports), slippageBasisPoint, bondingCurve, o)
+		}
 		if err != nil {
-			return "", fmt.Errorf("failed to get buy instructions: %w", err)
+			return nil, fmt.Errorf("failed to get buy instructions: %w", err)
 		}
 		instructions = append(instructions, buyInstructions...)
+		initialBuy, err = initialBuyFill(buyInstructions[len(buyInstructions)-1], bondingCurve, feeBasisPoints)
+		if err != nil {
+			return nil, fmt.Errorf("can't compute initial buy fill: %w", err)
+		}
 	}
-	tx, err := solana.NewTransaction(
-		instructions,
-		recent.Value.Blockhash,
-		solana.TransactionPayer(user.PublicKey()),
-	)
+	instructions = append(instructions, o.trailingInstructions()...)
+	tx, err := buildTransaction(ctx, rpcClient, instructions, o, user, mint)
 	if err != nil {
-		return "", fmt.Errorf("error while creating new transaction: %w", err)
+		return nil, err
 	}
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			if user.PublicKey().Equals(key) {
-				return &user
-			}
-			if mint.PublicKey().Equals(key) {
-				return &mint.PrivateKey
-			}
-			return nil
-		},
-	)
-	if err != nil {
-		return "", fmt.Errorf("can't sign transaction: %w", err)
+	if o.dryRun {
+		return &CreateResult{
+			Signature:    tx.Signatures[0],
+			Mint:         mint.PublicKey(),
+			BondingCurve: bondingCurveData.BondingCurve,
+			MetadataUri:  uri,
+			Transaction:  tx,
+			InitialBuy:   initialBuy,
+		}, nil
 	}
 	// Send transaction, and wait for confirmation:
-	sig, err := confirm.SendAndConfirmTransaction(
-		context.TODO(),
-		rpcClient,
-		wsClient,
-		tx,
-	)
+	build := func() (*solana.Transaction, error) {
+		return buildTransaction(ctx, rpcClient, instructions, o, user, mint)
+	}
+	send := func(tx *solana.Transaction) (*Confirmation, error) {
+		return sendAndConfirmTransaction(ctx, rpcClient, wsClient, tx, o)
+	}
+	_, confirmation, err := sendWithBlockhashRetry(tx, build, send, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't send and confirm new transaction: %w", MapProgramError(err))
+	}
+	o.logger.Info("created token", "signature", confirmation.Signature, "mint", mint.PublicKey(), "slot", confirmation.Slot)
+	return &CreateResult{
+		Signature:    confirmation.Signature,
+		Slot:         confirmation.Slot,
+		Mint:         mint.PublicKey(),
+		BondingCurve: bondingCurveData.BondingCurve,
+		MetadataUri:  uri,
+		InitialBuy:   initialBuy,
+	}, nil
+}
+
+// initialBuyFill returns the fill of the buy instruction against the initial bonding curve.
+func initialBuyFill(buyInstruction solana.Instruction, bondingCurve *BondingCurveData, feeBasisPoints uint64) (*InitialBuyFill, error) {
+	instruction, ok := buyInstruction.(*pump.Instruction)
+	if !ok {
+		return nil, fmt.Errorf("unexpected instruction %T", buyInstruction)
+	}
+	buy, ok := instruction.Impl.(pump.Buy)
+	if !ok {
+		return nil, fmt.Errorf("unexpected instruction %T, want a buy", instruction.Impl)
+	}
+	sol, err := chargedBuyCost(*buy.Amount, bondingCurve, feeBasisPoints)
+	if err != nil {
+		return nil, err
+	}
+	return &InitialBuyFill{
+		Tokens:     TokenAmount(*buy.Amount),
+		SolCost:    Lamports(sol.Uint64()),
+		MaxSolCost: Lamports(*buy.MaxSolCost),
+	}, nil
+}
+
+// initialBuyTokens returns the amount of tokens to buy in the create transaction, from the initial buy options
+// expressed in tokens, 0 if the initial buy is expressed in SOL. It is clamped to the real token reserves,
+// as the creator can't buy more than the bonding curve holds.
+func initialBuyTokens(global *pump.Global, bondingCurve *BondingCurveData, o *options) (TokenAmount, error) {
+	tokenAmount := o.initialBuyTokens
+	if o.initialBuyPercentage > 0 {
+		if o.initialBuyPercentage > 100 {
+			return 0, fmt.Errorf("initial buy percentage %v is over 100", o.initialBuyPercentage)
+		}
+		tokenAmount = TokenAmount(float64(global.TokenTotalSupply) * o.initialBuyPercentage / 100)
+	}
+	reserves := TokenAmount(bondingCurve.RealTokenReserves.Uint64())
+	if tokenAmount > reserves {
+		o.logger.Warn("initial buy is over the bonding curve reserves, clamping it", "tokenAmount", tokenAmount, "reserves", reserves)
+	}
+	return min(tokenAmount, reserves), nil
+}
+
+// initialBuyLamports returns the amount of SOL to spend in the create transaction.
+// It is clamped to maxInitialBuyLamports, as the creator can't buy more than the bonding curve holds.
+func initialBuyLamports(bondingCurve *BondingCurveData, feeBasisPoints uint64, buyAmountLamports Lamports, o *options) (Lamports, error) {
+	maxLamports, err := maxInitialBuyLamports(bondingCurve, feeBasisPoints)
+	if err != nil {
+		return 0, err
+	}
+	if buyAmountLamports > maxLamports {
+		o.logger.Warn("initial buy is over the bonding curve reserves, clamping it", "buyAmountLamports", buyAmountLamports, "maxLamports", maxLamports)
+	}
+	return min(buyAmountLamports, maxLamports), nil
+}
+
+// initialBuyInstructions returns the instructions of the create transaction buying exactly the amount of tokens
+// from the initial bonding curve, creating the associated token account of the user. Nothing trades before the creator,
+// so the slippage only raises the SOL the buy allows to spend over its cost.
+func initialBuyInstructions(mint solana.PublicKey, user solana.PublicKey, tokenAmount TokenAmount, bondingCurve *BondingCurveData, feeBasisPoints uint64, slippageBasisPoint uint, o *options) ([]solana.Instruction, error) {
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bonding curve data: %w", err)
+	}
+	ata, err := findAssociatedTokenAddress(user, mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	ataInstr, err := newCreateAtaInstruction(user, user, mint, o.getTokenProgram())
 	if err != nil {
-		return "", fmt.Errorf("can't send and confirm new transaction: %w", err)
+		return nil, fmt.Errorf("can't create associated token account: %w", err)
 	}
-	return sig.String(), nil
+	cost, err := calculateBuyCost(uint64(tokenAmount), bondingCurve, feeBasisPoints)
+	if err != nil {
+		return nil, err
+	}
+	maxSolCost := addSlippage(cost, slippageBasisPoint).Uint64()
+	if o.maxSolCost > 0 {
+		if cost.Uint64() > uint64(o.maxSolCost) {
+			return nil, fmt.Errorf("initial buy of %d tokens costs %s lamports, over the max SOL cost %d", tokenAmount, cost, o.maxSolCost)
+		}
+		maxSolCost = min(maxSolCost, uint64(o.maxSolCost))
+	}
+	return []solana.Instruction{ataInstr, newBuyInstruction(uint64(tokenAmount), maxSolCost, mint, user, bondingCurveData, ata, o)}, nil
+}
+
+// maxInitialBuyLamports returns the most SOL the creator can spend in the create transaction,
+// i.e. the cost of all the real token reserves of the initial bonding curve, which completes it.
+func maxInitialBuyLamports(bondingCurve *BondingCurveData, feeBasisPoints uint64) (Lamports, error) {
+	sol, err := calculateBuyCost(bondingCurve.RealTokenReserves.Uint64(), bondingCurve, feeBasisPoints)
+	if err != nil {
+		return 0, err
+	}
+	return Lamports(sol.Uint64()), nil
+}
+
+// MaxInitialBuyLamports returns the most SOL CreateToken can spend on its initial buy, buying all the tokens
+// of the bonding curve. Larger amounts are clamped to it by CreateToken.
+func MaxInitialBuyLamports(ctx context.Context, rpcClient RPCClient, opts ...Option) (Lamports, error) {
+	o := newOptions(opts)
+	global, err := getGlobal(ctx, rpcClient)
+	if err != nil {
+		return 0, fmt.Errorf("can't fetch global account: %w", err)
+	}
+	feeBasisPoints := global.FeeBasisPoints
+	if o.feeBasisPoints != nil {
+		feeBasisPoints = *o.feeBasisPoints
+	}
+	return maxInitialBuyLamports(initialBondingCurve(global), feeBasisPoints)
 }
 
 type CreateTokenMetadataRequest struct {
@@ -203,8 +420,14 @@ type CreateTokenMetadataRequest struct {
 	Twitter     string
 	Telegram    string
 	Website     string
+	// Header is added to the request to the pump.fun IPFS endpoint, e.g. a User-Agent or cookies
+	// to get through its bot protection. The User-Agent defaults to the one of a browser.
+	Header http.Header
 }
 
+// defaultUserAgent is the User-Agent of the requests to pump.fun, as it may block the one of Go.
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36"
+
 type CreateTokenMetadataResponse struct {
 	Name        string `json:"name"`
 	Symbol      string `json:"symbol"`
@@ -219,15 +442,35 @@ type CreateTokenMetadataResponse struct {
 	MetadataUri string `json:"metadataUri"`
 }
 
-func CreateTokenMetadata(client *http.Client, create CreateTokenMetadataRequest) (*CreateTokenMetadataResponse, error) {
+// pumpFunIpfsUrl is the endpoint of pump.fun uploading the metadata of a token to IPFS.
+var pumpFunIpfsUrl = "https://pump.fun/api/ipfs"
+
+// minHTTPRetryBackoff is the wait before the first retry of an HTTP request, doubled after each retry.
+var minHTTPRetryBackoff = time.Second
+
+// CreateTokenMetadata downloads the image of the token, and uploads it with the metadata to IPFS through pump.fun.
+// Both requests are bound to the context, so set a deadline to avoid hanging on a slow endpoint.
+func CreateTokenMetadata(ctx context.Context, client *http.Client, create CreateTokenMetadataRequest, opts ...Option) (*CreateTokenMetadataResponse, error) {
+	o := newOptions(opts)
+	if err := validateNameAndSymbol(create.Name, create.Symbol); err != nil {
+		return nil, fmt.Errorf("invalid token metadata: %w", err)
+	}
+	if create.Filename == "" {
+		return nil, fmt.Errorf("invalid token metadata: image filename is required")
+	}
+	if err := normalizeSocialLinks(&create); err != nil {
+		return nil, fmt.Errorf("invalid token metadata: %w", err)
+	}
 	// Create a buffer to hold the form data
 	var b bytes.Buffer
 	writer := multipart.NewWriter(&b)
 
 	// Add the file from URL
-	resp, err := http.Get(create.Filename)
+	resp, err := doWithRetries(ctx, client, o, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, create.Filename, nil)
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("can't download image: %w", err)
 	}
 	defer resp.Body.Close()
 	// Create the form file
@@ -256,17 +499,21 @@ func CreateTokenMetadata(client *http.Client, create CreateTokenMetadataRequest)
 		return nil, err
 	}
 
-	// Create the HTTP request
-	req, err := http.NewRequest("POST", "https://pump.fun/api/ipfs", &b)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// Perform the HTTP request
-	resp, err = client.Do(req)
+	// Perform the HTTP request, with a new body for each attempt.
+	resp, err = doWithRetries(ctx, client, o, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, pumpFunIpfsUrl, bytes.NewReader(b.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", defaultUserAgent)
+		for key, values := range create.Header {
+			req.Header[http.CanonicalHeaderKey(key)] = values
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("can't upload metadata: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -279,3 +526,34 @@ func CreateTokenMetadata(client *http.Client, create CreateTokenMetadataRequest)
 
 	return &result, nil
 }
+
+// doWithRetries sends the request made by newRequest, and retries it with backoff, as many times as the options allow,
+// while the response is a server error or a rate limit. It returns an error unless the response is a 200.
+func doWithRetries(ctx context.Context, client *http.Client, o *options, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	backoff := minHTTPRetryBackoff
+	for retry := 0; ; retry++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		resp.Body.Close()
+		retryable := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		if !retryable || retry >= o.httpRetries {
+			return nil, fmt.Errorf("status code %d", resp.StatusCode)
+		}
+		o.logger.Warn("retrying HTTP request", "url", req.URL, "statusCode", resp.StatusCode, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
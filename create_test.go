@@ -0,0 +1,319 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestValidateTokenMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		symbol  string
+		uri     string
+		wantErr bool
+	}{
+		{"valid", "TEST", "TEST", "https://example.com", false},
+		{"name at limit", strings.Repeat("a", maxNameLength), "TEST", "https://example.com", false},
+		{"name over limit", strings.Repeat("a", maxNameLength+1), "TEST", "https://example.com", true},
+		{"empty name", "", "TEST", "https://example.com", true},
+		{"symbol at limit", "TEST", strings.Repeat("a", maxSymbolLength), "https://example.com", false},
+		{"symbol over limit", "TEST", strings.Repeat("a", maxSymbolLength+1), "https://example.com", true},
+		{"empty symbol", "TEST", "", "https://example.com", true},
+		{"uri at limit", "TEST", "TEST", strings.Repeat("a", maxUriLength), false},
+		{"uri over limit", "TEST", "TEST", strings.Repeat("a", maxUriLength+1), true},
+		{"empty uri", "TEST", "TEST", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTokenMetadata(tt.token, tt.symbol, tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateTokenMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitialBuyTokens(t *testing.T) {
+	global := &pump.Global{
+		InitialVirtualTokenReserves: 1073000000000000,
+		InitialVirtualSolReserves:   30000000000,
+		InitialRealTokenReserves:    793100000000000,
+		TokenTotalSupply:            1000000000000000,
+		FeeBasisPoints:              100,
+	}
+	bondingCurve := initialBondingCurve(global)
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    TokenAmount
+		wantErr bool
+	}{
+		{"lamports", nil, 0, false},
+		{"tokens", []Option{WithInitialBuyTokens(10000000000000)}, 10000000000000, false},
+		{"percentage", []Option{WithInitialBuyPercentage(5)}, 50000000000000, false},
+		{"percentage over 100", []Option{WithInitialBuyPercentage(101)}, 0, true},
+		// Clamped to the reserves, the bonding curve can't sell more.
+		{"more than the reserves", []Option{WithInitialBuyTokens(TokenAmount(global.InitialRealTokenReserves + 1))}, TokenAmount(global.InitialRealTokenReserves), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := initialBuyTokens(global, bondingCurve, newOptions(tt.opts))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("initialBuyTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Fatalf("initialBuyTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialBuyLamportsClamped(t *testing.T) {
+	global := &pump.Global{
+		InitialVirtualTokenReserves: 1073000000000000,
+		InitialVirtualSolReserves:   30000000000,
+		InitialRealTokenReserves:    793100000000000,
+		TokenTotalSupply:            1000000000000000,
+		FeeBasisPoints:              100,
+	}
+	bondingCurve := initialBondingCurve(global)
+	maxLamports, err := maxInitialBuyLamports(bondingCurve, global.FeeBasisPoints)
+	if err != nil {
+		t.Fatalf("maxInitialBuyLamports() error = %s", err)
+	}
+	sol, err := initialBuyLamports(bondingCurve, global.FeeBasisPoints, SolToLamports(1000), newOptions(nil))
+	if err != nil {
+		t.Fatalf("initialBuyLamports() error = %s", err)
+	}
+	if sol != maxLamports {
+		t.Fatalf("initialBuyLamports() = %d, want %d", sol, maxLamports)
+	}
+}
+
+func TestNormalizeSocialLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		create  CreateTokenMetadataRequest
+		want    CreateTokenMetadataRequest
+		wantErr bool
+	}{
+		{"empty", CreateTokenMetadataRequest{}, CreateTokenMetadataRequest{}, false},
+		{
+			"handles",
+			CreateTokenMetadataRequest{Twitter: "@foo", Telegram: "@foobar"},
+			CreateTokenMetadataRequest{Twitter: "https://x.com/foo", Telegram: "https://t.me/foobar"},
+			false,
+		},
+		{
+			"links without scheme",
+			CreateTokenMetadataRequest{Twitter: "twitter.com/foo", Website: "foo.com"},
+			CreateTokenMetadataRequest{Twitter: "https://twitter.com/foo", Website: "https://foo.com"},
+			false,
+		},
+		{
+			"links",
+			CreateTokenMetadataRequest{Telegram: "https://t.me/foobar", Website: "http://foo.com/bar"},
+			CreateTokenMetadataRequest{Telegram: "https://t.me/foobar", Website: "http://foo.com/bar"},
+			false,
+		},
+		{"invalid website", CreateTokenMetadataRequest{Website: "not a website"}, CreateTokenMetadataRequest{}, true},
+		{"invalid scheme", CreateTokenMetadataRequest{Website: "ftp://foo.com"}, CreateTokenMetadataRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeSocialLinks(&tt.create)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeSocialLinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(tt.create, tt.want) {
+				t.Fatalf("normalizeSocialLinks() = %+v, want %+v", tt.create, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTokenMetadataRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		retries  int
+		wantErr  bool
+	}{
+		{"no failure", 0, http.StatusServiceUnavailable, 0, false},
+		{"server error retried", 2, http.StatusServiceUnavailable, 2, false},
+		{"rate limit retried", 1, http.StatusTooManyRequests, 1, false},
+		{"not enough retries", 2, http.StatusServiceUnavailable, 1, true},
+		{"client error not retried", 1, http.StatusBadRequest, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte("image"))
+					return
+				}
+				uploads++
+				if err := r.ParseMultipartForm(1 << 20); err != nil || r.FormValue("name") != "Token" {
+					http.Error(w, "bad form", http.StatusBadRequest)
+					return
+				}
+				if uploads <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				json.NewEncoder(w).Encode(CreateTokenMetadataResponse{Name: "Token", MetadataUri: "ipfs://metadata"})
+			}))
+			defer server.Close()
+			defer func(url string) { pumpFunIpfsUrl = url }(pumpFunIpfsUrl)
+			pumpFunIpfsUrl = server.URL + "/api/ipfs"
+			defer func(backoff time.Duration) { minHTTPRetryBackoff = backoff }(minHTTPRetryBackoff)
+			minHTTPRetryBackoff = time.Millisecond
+
+			create := CreateTokenMetadataRequest{Name: "Token", Symbol: "TKN", Filename: server.URL + "/image.png"}
+			out, err := CreateTokenMetadata(context.Background(), server.Client(), create, WithHTTPRetries(tt.retries))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateTokenMetadata() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err == nil && out.MetadataUri != "ipfs://metadata" {
+				t.Fatalf("CreateTokenMetadata() MetadataUri = %q, want %q", out.MetadataUri, "ipfs://metadata")
+			}
+		})
+	}
+}
+
+func TestCreateTokenMetadataContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	create := CreateTokenMetadataRequest{Name: "Token", Symbol: "TKN", Filename: server.URL + "/image.png"}
+	if _, err := CreateTokenMetadata(ctx, server.Client(), create); err == nil {
+		t.Fatal("CreateTokenMetadata() error = nil, want the context deadline")
+	}
+}
+
+func TestCreateTokenInitialBuy(t *testing.T) {
+	global := &pump.Global{
+		InitialVirtualTokenReserves: 1073000000000000,
+		InitialVirtualSolReserves:   30000000000,
+		InitialRealTokenReserves:    793100000000000,
+		TokenTotalSupply:            1000000000000000,
+		FeeBasisPoints:              100,
+	}
+	cachedGlobal.Store(&cachedGlobalAccount{global: global, fetchedAt: time.Now()})
+	defer cachedGlobal.Store(nil)
+	user := solana.NewWallet().PrivateKey
+	tests := []struct {
+		name     string
+		lamports Lamports
+		slippage uint
+		opts     []Option
+		want     *InitialBuyFill
+	}{
+		{"no initial buy", 0, 0, nil, nil},
+		{"without slippage", 1000000000, 0, nil, &InitialBuyFill{Tokens: 34281150129546, SolCost: 1000000000, MaxSolCost: 1000000000}},
+		{"with slippage", 1000000000, 1000, nil, &InitialBuyFill{Tokens: 30853035116591, SolCost: 897039472, MaxSolCost: 1000000000}},
+		// The slippage of a buy in tokens is on the SOL it can spend, the tokens are exact.
+		{"tokens", 0, 0, []Option{WithInitialBuyTokens(10000000000000)}, &InitialBuyFill{Tokens: 10000000000000, SolCost: 285042333, MaxSolCost: 285042334}},
+		{"tokens with slippage", 0, 1000, []Option{WithInitialBuyTokens(10000000000000)}, &InitialBuyFill{Tokens: 10000000000000, SolCost: 285042333, MaxSolCost: 313546568}},
+		{"percentage", 0, 1000, []Option{WithInitialBuyPercentage(5)}, &InitialBuyFill{Tokens: 50000000000000, SolCost: 1480938416, MaxSolCost: 1629032259}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := CreateToken(context.Background(), &mockRPCClient{}, nil, user, solana.NewWallet().PrivateKey, "Token", "TKN", "https://example.com/metadata.json", tt.lamports, tt.slippage, append(tt.opts, WithDryRun())...)
+			if err != nil {
+				t.Fatalf("CreateToken() error = %s", err)
+			}
+			if !reflect.DeepEqual(out.InitialBuy, tt.want) {
+				t.Fatalf("CreateToken() initial buy = %+v, want %+v", out.InitialBuy, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTokenTokenProgram(t *testing.T) {
+	cachedGlobal.Store(&cachedGlobalAccount{global: &pump.Global{
+		InitialVirtualTokenReserves: 1073000000000000,
+		InitialVirtualSolReserves:   30000000000,
+		InitialRealTokenReserves:    793100000000000,
+		TokenTotalSupply:            1000000000000000,
+		FeeBasisPoints:              100,
+	}, fetchedAt: time.Now()})
+	defer cachedGlobal.Store(nil)
+	mint := solana.NewWallet().PrivateKey
+	out, err := CreateToken(context.Background(), &mockRPCClient{}, nil, solana.NewWallet().PrivateKey, mint, "Token", "TKN", "https://example.com/metadata.json", 1000000000, 0, WithDryRun(), WithTokenProgram(solana.Token2022ProgramID))
+	if err != nil {
+		t.Fatalf("CreateToken() error = %s", err)
+	}
+	associatedBondingCurve, err := findAssociatedTokenAddress(out.BondingCurve, mint.PublicKey(), solana.Token2022ProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The create and the initial buy, in this order, both use the Token-2022 associated bonding curve.
+	var pumpInstructions [][]*solana.AccountMeta
+	for _, instruction := range out.Transaction.Message.Instructions {
+		if !out.Transaction.Message.AccountKeys[instruction.ProgramIDIndex].Equals(pump.ProgramID) {
+			continue
+		}
+		accounts, err := instruction.ResolveInstructionAccounts(&out.Transaction.Message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pumpInstructions = append(pumpInstructions, accounts)
+	}
+	if len(pumpInstructions) != 2 {
+		t.Fatalf("CreateToken() has %d pump.fun instructions, want 2", len(pumpInstructions))
+	}
+	for i, want := range []struct{ associatedBondingCurve, tokenProgram int }{{3, 9}, {4, 8}} {
+		accounts := pumpInstructions[i]
+		if got := accounts[want.associatedBondingCurve].PublicKey; !got.Equals(associatedBondingCurve) {
+			t.Fatalf("instruction %d associated bonding curve = %s, want %s", i, got, associatedBondingCurve)
+		}
+		if got := accounts[want.tokenProgram].PublicKey; !got.Equals(solana.Token2022ProgramID) {
+			t.Fatalf("instruction %d token program = %s, want %s", i, got, solana.Token2022ProgramID)
+		}
+	}
+}
+
+// opaqueSigner is a Signer that keeps its private key to itself, like an HSM.
+type opaqueSigner struct {
+	key solana.PrivateKey
+}
+
+func (s opaqueSigner) PublicKey() solana.PublicKey {
+	return s.key.PublicKey()
+}
+
+func (s opaqueSigner) Sign(message []byte) (solana.Signature, error) {
+	return s.key.Sign(message)
+}
+
+func TestCreateTokenMintSigner(t *testing.T) {
+	user := solana.NewWallet().PrivateKey
+	mint := opaqueSigner{key: solana.NewWallet().PrivateKey}
+	out, err := CreateToken(context.Background(), &mockRPCClient{}, nil, user, mint, "Token", "TKN", "https://example.com/metadata.json", 0, 0, WithDryRun())
+	if err != nil {
+		t.Fatalf("CreateToken() error = %s", err)
+	}
+	if !out.Mint.Equals(mint.PublicKey()) {
+		t.Fatalf("CreateToken() mint = %s, want %s", out.Mint, mint.PublicKey())
+	}
+	if err := out.Transaction.VerifySignatures(); err != nil {
+		t.Fatalf("invalid signatures: %s", err)
+	}
+}
@@ -0,0 +1,23 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// RequestDevnetAirdrop requests an airdrop of lamports to the wallet, and waits for its confirmation.
+// It only works on devnet and testnet, and is meant for integration tests to fund a fresh wallet.
+func RequestDevnetAirdrop(ctx context.Context, rpcClient *rpc.Client, wallet solana.PublicKey, lamports Lamports) (solana.Signature, error) {
+	sig, err := rpcClient.RequestAirdrop(ctx, wallet, uint64(lamports), rpc.CommitmentConfirmed)
+	if err != nil {
+		return sig, fmt.Errorf("can't request airdrop: %w", err)
+	}
+	o := newOptions([]Option{WithConfirmCommitment(rpc.CommitmentConfirmed)})
+	if err := waitForConfirmation(ctx, rpcClient, nil, sig, o); err != nil {
+		return sig, fmt.Errorf("can't confirm airdrop: %w", err)
+	}
+	return sig, nil
+}
@@ -0,0 +1,258 @@
+package pumpdotfunsdk
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// Errors a failed transaction is mapped onto, to be checked with errors.Is.
+var (
+	// ErrSlippageExceeded is returned when the price moved more than the slippage allowed.
+	ErrSlippageExceeded = errors.New("slippage exceeded")
+	// ErrInsufficientFunds is returned when the user can't pay for the trade, the fees, or the rent.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrBondingCurveComplete is returned when trading a token that migrated to Raydium.
+	ErrBondingCurveComplete = errors.New("bonding curve complete")
+)
+
+// InsufficientFundsError is returned by the balance check, when the wallet can't pay for the transaction.
+// It matches ErrInsufficientFunds.
+type InsufficientFundsError struct {
+	Required  Lamports
+	Available Lamports
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds: %d lamports required, %d available, %d missing", e.Required, e.Available, e.Required-e.Available)
+}
+
+func (e *InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// ProgramError is a custom error of the pump.fun program, as defined in its IDL.
+type ProgramError struct {
+	Code int64
+	Name string
+	Msg  string
+}
+
+func (e *ProgramError) Error() string {
+	return fmt.Sprintf("pump.fun error %d %s: %s", e.Code, e.Name, e.Msg)
+}
+
+// Is makes the program errors match the generic errors of this package.
+func (e *ProgramError) Is(target error) bool {
+	switch target {
+	case ErrSlippageExceeded:
+		return e == ErrTooMuchSolRequired || e == ErrTooLittleSolReceived
+	case ErrBondingCurveComplete:
+		return e == ErrProgramBondingCurveComplete
+	}
+	return false
+}
+
+// Custom errors of the pump.fun program.
+var (
+	ErrNotAuthorized                = &ProgramError{6000, "NotAuthorized", "The given account is not authorized to execute this instruction."}
+	ErrAlreadyInitialized           = &ProgramError{6001, "AlreadyInitialized", "The program is already initialized."}
+	ErrTooMuchSolRequired           = &ProgramError{6002, "TooMuchSolRequired", "slippage: Too much SOL required to buy the given amount of tokens."}
+	ErrTooLittleSolReceived         = &ProgramError{6003, "TooLittleSolReceived", "slippage: Too little SOL received to sell the given amount of tokens."}
+	ErrMintDoesNotMatchBondingCurve = &ProgramError{6004, "MintDoesNotMatchBondingCurve", "The mint does not match the bonding curve."}
+	ErrProgramBondingCurveComplete  = &ProgramError{6005, "BondingCurveComplete", "The bonding curve has completed and liquidity migrated to raydium."}
+	ErrBondingCurveNotComplete      = &ProgramError{6006, "BondingCurveNotComplete", "The bonding curve has not completed."}
+	ErrNotInitialized               = &ProgramError{6007, "NotInitialized", "The program is not initialized."}
+)
+
+var programErrors = map[int64]*ProgramError{}
+
+func init() {
+	for _, err := range []*ProgramError{
+		ErrNotAuthorized,
+		ErrAlreadyInitialized,
+		ErrTooMuchSolRequired,
+		ErrTooLittleSolReceived,
+		ErrMintDoesNotMatchBondingCurve,
+		ErrProgramBondingCurveComplete,
+		ErrBondingCurveNotComplete,
+		ErrNotInitialized,
+	} {
+		programErrors[err.Code] = err
+	}
+}
+
+// customErrorRegexp matches the instruction index and the custom error code in the message of a failed simulation,
+// e.g. "Error processing Instruction 2: custom program error: 0x1772".
+var customErrorRegexp = regexp.MustCompile(`Instruction (\d+): custom program error: 0x([0-9a-fA-F]+)`)
+
+// errCodeInsufficientFunds is the custom error code of both the system program and the token program,
+// when an account doesn't hold enough lamports or tokens.
+const errCodeInsufficientFunds = 1
+
+// transactionError is returned when a transaction was confirmed, but failed while executing.
+type transactionError struct {
+	err interface{}
+}
+
+func (e *transactionError) Error() string {
+	return fmt.Sprintf("confirmed transaction with execution error: %v", e.err)
+}
+
+// transactionProgramsError attaches the programs of the instructions of a transaction to its error,
+// so that MapProgramError knows which program failed.
+type transactionProgramsError struct {
+	err      error
+	programs []solana.PublicKey
+}
+
+func (e *transactionProgramsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transactionProgramsError) Unwrap() error {
+	return e.err
+}
+
+// withTransactionPrograms attaches the programs of the instructions of the transaction to the error, if any.
+func withTransactionPrograms(err error, tx *solana.Transaction) error {
+	if err == nil || tx == nil {
+		return err
+	}
+	programs := make([]solana.PublicKey, len(tx.Message.Instructions))
+	for i, instruction := range tx.Message.Instructions {
+		program, err := tx.Message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		if err != nil {
+			continue
+		}
+		programs[i] = program
+	}
+	return &transactionProgramsError{err: err, programs: programs}
+}
+
+// MapTransactionError maps the error of the failed transaction tx like MapProgramError,
+// e.g. for a transaction built with BuyInstructions and sent by the caller.
+func MapTransactionError(err error, tx *solana.Transaction) error {
+	return MapProgramError(withTransactionPrograms(err, tx))
+}
+
+// MapProgramError wraps the error of a failed transaction with the matching error of this package,
+// if any, so callers can use errors.Is instead of parsing the error message.
+// Custom errors of the pump.fun program are mapped to their *ProgramError, e.g. ErrTooMuchSolRequired,
+// which also matches the generic errors, e.g. ErrSlippageExceeded. They are only mapped when the failed instruction
+// is the one of pump.fun, which requires the error to carry the programs of the transaction, as the errors
+// of this package do; see MapTransactionError for the others.
+// The errors returned by CreateToken, BuyToken and SellToken are already mapped.
+func MapProgramError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var programs []solana.PublicKey
+	var programsErr *transactionProgramsError
+	if errors.As(err, &programsErr) {
+		programs = programsErr.programs
+	}
+	var txErr interface{}
+	var rpcErr *jsonrpc.RPCError
+	var execErr *transactionError
+	switch {
+	case errors.As(err, &rpcErr):
+		// Preflight errors hold the transaction error in their data.
+		data, ok := rpcErr.Data.(map[string]interface{})
+		if !ok {
+			return err
+		}
+		txErr = data["err"]
+	case errors.As(err, &execErr):
+		txErr = execErr.err
+	default:
+		if match := customErrorRegexp.FindStringSubmatch(err.Error()); match != nil {
+			index, _ := strconv.Atoi(match[1])
+			code, _ := strconv.ParseInt(match[2], 16, 64)
+			if mapped := customErrorCodeToError(code, instructionProgram(programs, index)); mapped != nil {
+				return fmt.Errorf("%w: %w", mapped, err)
+			}
+		}
+		return err
+	}
+	if mapped := transactionErrorToError(txErr, programs); mapped != nil {
+		return fmt.Errorf("%w: %w", mapped, err)
+	}
+	return err
+}
+
+// transactionErrorToError maps a transaction error, as returned by the RPC, to an error of this package.
+// e.g. {"InstructionError":[2,{"Custom":6002}]} or "InsufficientFundsForFee".
+// programs are the programs of the instructions of the transaction, to tell which one failed.
+func transactionErrorToError(txErr interface{}, programs []solana.PublicKey) error {
+	switch v := txErr.(type) {
+	case string:
+		return transactionErrorNameToError(v)
+	case map[string]interface{}:
+		for name, value := range v {
+			if name != "InstructionError" {
+				return transactionErrorNameToError(name)
+			}
+			instructionErr, ok := value.([]interface{})
+			if !ok || len(instructionErr) != 2 {
+				return nil
+			}
+			index, err := strconv.Atoi(fmt.Sprint(instructionErr[0]))
+			if err != nil {
+				return nil
+			}
+			custom, ok := instructionErr[1].(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			code, err := strconv.ParseInt(fmt.Sprint(custom["Custom"]), 10, 64)
+			if err != nil {
+				return nil
+			}
+			return customErrorCodeToError(code, instructionProgram(programs, index))
+		}
+	}
+	return nil
+}
+
+func transactionErrorNameToError(name string) error {
+	switch name {
+	case "InsufficientFundsForFee", "InsufficientFundsForRent", "AccountNotFound":
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// customErrorCodeToError maps the custom error code of the program of the failed instruction.
+// The codes of pump.fun are mapped, including the insufficient funds of the programs it calls,
+// and the insufficient funds of the token programs. The codes of the other programs aren't.
+func customErrorCodeToError(code int64, program solana.PublicKey) error {
+	switch {
+	case program.Equals(pump.ProgramID):
+		if err, ok := programErrors[code]; ok {
+			return err
+		}
+		if code == errCodeInsufficientFunds {
+			return ErrInsufficientFunds
+		}
+	case program.Equals(token.ProgramID) || program.Equals(solana.Token2022ProgramID):
+		if code == errCodeInsufficientFunds {
+			return ErrInsufficientFunds
+		}
+	}
+	return nil
+}
+
+// instructionProgram returns the program of the instruction at the index, the zero key if unknown.
+func instructionProgram(programs []solana.PublicKey, index int) solana.PublicKey {
+	if index < 0 || index >= len(programs) {
+		return solana.PublicKey{}
+	}
+	return programs[index]
+}
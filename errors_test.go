@@ -0,0 +1,190 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	cb "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// Programs of the instructions of a buy creating the associated token account, and of a sell.
+var (
+	buyPrograms  = []solana.PublicKey{cb.ProgramID, cb.ProgramID, associatedtokenaccount.ProgramID, pump.ProgramID}
+	sellPrograms = []solana.PublicKey{cb.ProgramID, cb.ProgramID, pump.ProgramID}
+)
+
+// withPrograms attaches the programs of the instructions of the failed transaction to the error.
+func withPrograms(err error, programs []solana.PublicKey) error {
+	return &transactionProgramsError{err: err, programs: programs}
+}
+
+// sellTransaction returns a transaction with the programs of a sell, failing at the pump.fun instruction 2.
+func sellTransaction(t *testing.T, payer solana.PublicKey) *solana.Transaction {
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			cb.NewSetComputeUnitLimitInstruction(computeUnitLimit).Build(),
+			cb.NewSetComputeUnitPriceInstruction(1).Build(),
+			solana.NewInstruction(pump.ProgramID, solana.AccountMetaSlice{solana.Meta(payer).WRITE().SIGNER()}, nil),
+		},
+		solana.Hash{},
+		solana.TransactionPayer(payer),
+	)
+	if err != nil {
+		t.Fatalf("can't create transaction: %s", err)
+	}
+	return tx
+}
+
+// rpcError decodes an RPC error payload the same way the RPC client does.
+func rpcError(t *testing.T, payload string) error {
+	decoder := json.NewDecoder(bytes.NewBufferString(payload))
+	decoder.UseNumber()
+	var rpcErr jsonrpc.RPCError
+	if err := decoder.Decode(&rpcErr); err != nil {
+		t.Fatalf("can't decode payload: %s", err)
+	}
+	return fmt.Errorf("can't send transaction: %w", &rpcErr)
+}
+
+func TestMapProgramError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     func(t *testing.T) error
+		want    error
+		generic error
+	}{
+		{
+			name: "buy slippage",
+			err: func(t *testing.T) error {
+				return withPrograms(rpcError(t, `{"code":-32002,"message":"Transaction simulation failed: Error processing Instruction 3: custom program error: 0x1772","data":{"accounts":null,"err":{"InstructionError":[3,{"Custom":6002}]},"logs":["Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P invoke [1]","Program log: Instruction: Buy","Program log: AnchorError thrown in programs/pump/src/lib.rs:227. Error Code: TooMuchSolRequired. Error Number: 6002. Error Message: slippage: Too much SOL required to buy the given amount of tokens..","Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P consumed 24381 of 249700 compute units","Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P failed: custom program error: 0x1772"],"returnData":null,"unitsConsumed":24681}}`), buyPrograms)
+			},
+			want:    ErrTooMuchSolRequired,
+			generic: ErrSlippageExceeded,
+		},
+		{
+			name: "sell slippage",
+			err: func(t *testing.T) error {
+				return withPrograms(rpcError(t, `{"code":-32002,"message":"Transaction simulation failed: Error processing Instruction 2: custom program error: 0x1773","data":{"accounts":null,"err":{"InstructionError":[2,{"Custom":6003}]},"logs":["Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P invoke [1]","Program log: Instruction: Sell","Program log: AnchorError thrown in programs/pump/src/lib.rs:300. Error Code: TooLittleSolReceived. Error Number: 6003. Error Message: slippage: Too little SOL received to sell the given amount of tokens..","Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P failed: custom program error: 0x1773"],"returnData":null,"unitsConsumed":31254}}`), sellPrograms)
+			},
+			want:    ErrTooLittleSolReceived,
+			generic: ErrSlippageExceeded,
+		},
+		{
+			name: "bonding curve complete",
+			err: func(t *testing.T) error {
+				return withPrograms(rpcError(t, `{"code":-32002,"message":"Transaction simulation failed: Error processing Instruction 2: custom program error: 0x1775","data":{"accounts":null,"err":{"InstructionError":[2,{"Custom":6005}]},"logs":[],"returnData":null,"unitsConsumed":12000}}`), sellPrograms)
+			},
+			want:    ErrProgramBondingCurveComplete,
+			generic: ErrBondingCurveComplete,
+		},
+		{
+			name: "insufficient lamports",
+			err: func(t *testing.T) error {
+				return withPrograms(rpcError(t, `{"code":-32002,"message":"Transaction simulation failed: Error processing Instruction 2: custom program error: 0x1","data":{"accounts":null,"err":{"InstructionError":[2,{"Custom":1}]},"logs":["Program 11111111111111111111111111111111 invoke [2]","Transfer: insufficient lamports 1000, need 100000000","Program 11111111111111111111111111111111 failed: custom program error: 0x1"],"returnData":null,"unitsConsumed":9000}}`), sellPrograms)
+			},
+			want:    ErrInsufficientFunds,
+			generic: ErrInsufficientFunds,
+		},
+		{
+			name: "insufficient funds for fee",
+			err: func(t *testing.T) error {
+				return rpcError(t, `{"code":-32002,"message":"Transaction simulation failed: Attempt to debit an account but found no record of a prior credit.","data":{"accounts":null,"err":"AccountNotFound","logs":[],"returnData":null,"unitsConsumed":0}}`)
+			},
+			want:    ErrInsufficientFunds,
+			generic: ErrInsufficientFunds,
+		},
+		{
+			name: "failed on confirmation",
+			err: func(t *testing.T) error {
+				var txErr interface{}
+				if err := json.Unmarshal([]byte(`{"InstructionError":[2,{"Custom":6003}]}`), &txErr); err != nil {
+					t.Fatal(err)
+				}
+				return withPrograms(&transactionError{err: txErr}, sellPrograms)
+			},
+			want:    ErrTooLittleSolReceived,
+			generic: ErrSlippageExceeded,
+		},
+		{
+			name: "message only",
+			err: func(t *testing.T) error {
+				return withPrograms(errors.New("Error processing Instruction 2: custom program error: 0x1776"), sellPrograms)
+			},
+			want: ErrBondingCurveNotComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err(t)
+			got := MapProgramError(err)
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("MapProgramError() = %v, want %v", got, tt.want)
+			}
+			if tt.generic != nil && !errors.Is(got, tt.generic) {
+				t.Fatalf("MapProgramError() = %v, want %v", got, tt.generic)
+			}
+			if !errors.Is(got, err) {
+				t.Fatalf("MapProgramError() = %v, doesn't wrap the original error", got)
+			}
+		})
+	}
+}
+
+func TestMapProgramErrorOtherProgram(t *testing.T) {
+	custom := func(index int, code int) error {
+		return &transactionError{err: map[string]interface{}{"InstructionError": []interface{}{index, map[string]interface{}{"Custom": code}}}}
+	}
+	withMemo := append(append([]solana.PublicKey{}, sellPrograms...), solana.MemoProgramID)
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"anchor error of an extra instruction", withPrograms(custom(3, 6002), withMemo), nil},
+		{"insufficient funds of the compute budget", withPrograms(custom(0, 1), sellPrograms), nil},
+		{"insufficient funds of the associated token account program", withPrograms(custom(2, 1), buyPrograms), nil},
+		{"insufficient funds of the token program", withPrograms(custom(0, 1), []solana.PublicKey{token.ProgramID}), ErrInsufficientFunds},
+		{"unknown programs", custom(2, 6003), nil},
+		{"message of an extra instruction", withPrograms(errors.New("Error processing Instruction 3: custom program error: 0x1772"), withMemo), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MapProgramError(tt.err)
+			if tt.want == nil {
+				if got != tt.err {
+					t.Fatalf("MapProgramError() = %v, want the original error", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.want) {
+				t.Fatalf("MapProgramError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapTransactionError(t *testing.T) {
+	tx := sellTransaction(t, solana.NewWallet().PublicKey())
+	err := &transactionError{err: map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": 6003}}}}
+	if got := MapTransactionError(err, tx); !errors.Is(got, ErrTooLittleSolReceived) || !errors.Is(got, err) {
+		t.Fatalf("MapTransactionError() = %v, want %v", got, ErrTooLittleSolReceived)
+	}
+}
+
+func TestMapProgramErrorUnknown(t *testing.T) {
+	err := errors.New("connection refused")
+	if got := MapProgramError(err); got != err {
+		t.Fatalf("MapProgramError() = %v, want the original error", got)
+	}
+	if got := MapProgramError(nil); got != nil {
+		t.Fatalf("MapProgramError(nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,130 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Discriminators of the pump.fun events, emitted in the program logs.
+var (
+	tradeEventDiscriminator  = [8]byte{189, 219, 127, 211, 78, 230, 97, 238}
+	createEventDiscriminator = [8]byte{27, 114, 169, 77, 222, 235, 99, 118}
+)
+
+// programDataPrefix is the prefix of the program logs holding anchor events.
+const programDataPrefix = "Program data: "
+
+// TradeEvent is emitted by pump.fun every time a token is bought or sold.
+type TradeEvent struct {
+	Mint                 solana.PublicKey
+	SolAmount            Lamports
+	TokenAmount          TokenAmount
+	IsBuy                bool
+	User                 solana.PublicKey
+	Timestamp            int64
+	VirtualSolReserves   uint64
+	VirtualTokenReserves uint64
+}
+
+// Price returns the price of the trade, in SOL per token.
+func (e *TradeEvent) Price() float64 {
+	if e.TokenAmount == 0 {
+		return 0
+	}
+	return LamportsToSol(e.SolAmount) / (float64(e.TokenAmount) / tokenUnit)
+}
+
+// SpotPrice returns the price of the token right after the trade, in SOL per token,
+// from the virtual reserves of the bonding curve.
+func (e *TradeEvent) SpotPrice() float64 {
+	if e.VirtualTokenReserves == 0 {
+		return 0
+	}
+	return LamportsToSol(Lamports(e.VirtualSolReserves)) / (float64(e.VirtualTokenReserves) / tokenUnit)
+}
+
+// PricePoint is the price of a token at the time of a trade.
+type PricePoint struct {
+	// Timestamp is the time of the trade, in seconds since the Unix epoch.
+	Timestamp int64
+	// Price is the spot price of the token after the trade, in SOL per token.
+	Price float64
+	// SolAmount is the SOL traded, e.g. to chart the volume.
+	SolAmount Lamports
+	IsBuy     bool
+}
+
+// CurvePriceHistory replays the trades of the mint, e.g. parsed from its transactions, and returns the price
+// of the token after each of them, oldest first, to chart it without an indexer. The trades of other mints are skipped.
+func CurvePriceHistory(mint solana.PublicKey, trades []TradeEvent) []PricePoint {
+	var history []PricePoint
+	for _, trade := range trades {
+		if !trade.Mint.Equals(mint) {
+			continue
+		}
+		history = append(history, PricePoint{Timestamp: trade.Timestamp, Price: trade.SpotPrice(), SolAmount: trade.SolAmount, IsBuy: trade.IsBuy})
+	}
+	slices.SortStableFunc(history, func(a, b PricePoint) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	return history
+}
+
+// parseTradeEvents returns all the trade events found in the logs of a transaction.
+func parseTradeEvents(logs []string) []TradeEvent {
+	var events []TradeEvent
+	for _, data := range eventsData(logs, tradeEventDiscriminator) {
+		var event TradeEvent
+		if err := bin.NewBorshDecoder(data).Decode(&event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// CreateEvent is emitted by pump.fun every time a token is created.
+type CreateEvent struct {
+	Name         string
+	Symbol       string
+	Uri          string
+	Mint         solana.PublicKey
+	BondingCurve solana.PublicKey
+	User         solana.PublicKey
+}
+
+// parseCreateEvents returns all the create events found in the logs of a transaction.
+func parseCreateEvents(logs []string) []CreateEvent {
+	var events []CreateEvent
+	for _, data := range eventsData(logs, createEventDiscriminator) {
+		var event CreateEvent
+		if err := bin.NewBorshDecoder(data).Decode(&event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// eventsData returns the data of the events with the given discriminator, without the discriminator.
+func eventsData(logs []string, discriminator [8]byte) [][]byte {
+	var out [][]byte
+	for _, log := range logs {
+		encoded, ok := strings.CutPrefix(log, programDataPrefix)
+		if !ok {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(data) < 8 || !bytes.Equal(data[:8], discriminator[:]) {
+			continue
+		}
+		out = append(out, data[8:])
+	}
+	return out
+}
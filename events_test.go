@@ -0,0 +1,72 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// eventLog encodes an event as it would appear in the program logs.
+func eventLog(t *testing.T, discriminator [8]byte, event interface{}) string {
+	buf := bytes.NewBuffer(discriminator[:])
+	if err := bin.NewBorshEncoder(buf).Encode(event); err != nil {
+		t.Fatalf("can't encode event: %s", err)
+	}
+	return programDataPrefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestParseTradeEvents(t *testing.T) {
+	want := TradeEvent{
+		Mint:                 solana.NewWallet().PublicKey(),
+		SolAmount:            100000000,
+		TokenAmount:          3500000000000,
+		IsBuy:                true,
+		User:                 solana.NewWallet().PublicKey(),
+		Timestamp:            1727000000,
+		VirtualSolReserves:   30100000000,
+		VirtualTokenReserves: 1069500000000000,
+	}
+	logs := []string{
+		"Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P invoke [1]",
+		"Program log: Instruction: Buy",
+		eventLog(t, tradeEventDiscriminator, want),
+		"Program data: bm90IGFuIGV2ZW50",
+		"Program 6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P success",
+	}
+	events := parseTradeEvents(logs)
+	if len(events) != 1 {
+		t.Fatalf("parseTradeEvents() returned %d events, want 1", len(events))
+	}
+	if events[0] != want {
+		t.Fatalf("parseTradeEvents() = %+v, want %+v", events[0], want)
+	}
+}
+
+func TestCurvePriceHistory(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	other := solana.NewWallet().PublicKey()
+	trades := []TradeEvent{
+		{Mint: mint, IsBuy: false, SolAmount: 10, Timestamp: 3, VirtualSolReserves: 40000000000, VirtualTokenReserves: 800000000000000},
+		{Mint: mint, IsBuy: true, SolAmount: 20, Timestamp: 1, VirtualSolReserves: 30000000000, VirtualTokenReserves: 1000000000000000},
+		{Mint: other, IsBuy: true, SolAmount: 30, Timestamp: 2, VirtualSolReserves: 60000000000, VirtualTokenReserves: 500000000000000},
+		{Mint: mint, IsBuy: true, SolAmount: 40, Timestamp: 2, VirtualSolReserves: 50000000000, VirtualTokenReserves: 500000000000000},
+	}
+	want := []PricePoint{
+		{Timestamp: 1, Price: 0.00000003, SolAmount: 20, IsBuy: true},
+		{Timestamp: 2, Price: 0.0000001, SolAmount: 40, IsBuy: true},
+		{Timestamp: 3, Price: 0.00000005, SolAmount: 10, IsBuy: false},
+	}
+	got := CurvePriceHistory(mint, trades)
+	if len(got) != len(want) {
+		t.Fatalf("CurvePriceHistory() returned %d points, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Timestamp != want[i].Timestamp || math.Abs(got[i].Price-want[i].Price) > 1e-15 || got[i].SolAmount != want[i].SolAmount || got[i].IsBuy != want[i].IsBuy {
+			t.Fatalf("CurvePriceHistory()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// errCodeTransactionSimulationFailed is the JSON-RPC error code returned when the preflight simulation of a transaction fails.
+const errCodeTransactionSimulationFailed = -32002
+
+// FailoverClient is an RPCClient calling its endpoints in order, until one of them succeeds.
+// A call fails over to the next endpoint on network errors, HTTP errors or rate limits,
+// but not when an endpoint answered that the account doesn't exist, or that the transaction fails.
+type FailoverClient struct {
+	clients []RPCClient
+}
+
+var _ RPCClient = (*FailoverClient)(nil)
+
+// NewFailoverClient returns a FailoverClient calling the clients in the given order.
+func NewFailoverClient(clients ...RPCClient) *FailoverClient {
+	return &FailoverClient{clients: clients}
+}
+
+// failover calls call with each client, until it succeeds or returns an error the other clients would return too.
+func failover[T any](ctx context.Context, f *FailoverClient, call func(RPCClient) (T, error)) (T, error) {
+	var zero T
+	if len(f.clients) == 0 {
+		return zero, errors.New("no RPC endpoint")
+	}
+	var errs []error
+	for _, client := range f.clients {
+		out, err := call(client)
+		if err == nil || !shouldFailover(ctx, err) {
+			return out, err
+		}
+		errs = append(errs, err)
+	}
+	return zero, fmt.Errorf("all %d RPC endpoints failed: %w", len(f.clients), errors.Join(errs...))
+}
+
+// shouldFailover returns whether another endpoint may succeed where one failed with err.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, rpc.ErrNotFound) {
+		return false
+	}
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == errCodeTransactionSimulationFailed {
+		return false
+	}
+	return true
+}
+
+func (f *FailoverClient) GetAccountInfo(ctx context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetAccountInfoResult, error) {
+		return c.GetAccountInfo(ctx, account)
+	})
+}
+
+func (f *FailoverClient) GetAccountInfoWithOpts(ctx context.Context, account solana.PublicKey, opts *rpc.GetAccountInfoOpts) (*rpc.GetAccountInfoResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetAccountInfoResult, error) {
+		return c.GetAccountInfoWithOpts(ctx, account, opts)
+	})
+}
+
+func (f *FailoverClient) GetMultipleAccountsWithOpts(ctx context.Context, accounts []solana.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetMultipleAccountsResult, error) {
+		return c.GetMultipleAccountsWithOpts(ctx, accounts, opts)
+	})
+}
+
+func (f *FailoverClient) GetBalance(ctx context.Context, account solana.PublicKey, commitment rpc.CommitmentType) (*rpc.GetBalanceResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetBalanceResult, error) {
+		return c.GetBalance(ctx, account, commitment)
+	})
+}
+
+func (f *FailoverClient) GetTokenAccountBalance(ctx context.Context, account solana.PublicKey, commitment rpc.CommitmentType) (*rpc.GetTokenAccountBalanceResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetTokenAccountBalanceResult, error) {
+		return c.GetTokenAccountBalance(ctx, account, commitment)
+	})
+}
+
+func (f *FailoverClient) GetTokenLargestAccounts(ctx context.Context, mint solana.PublicKey, commitment rpc.CommitmentType) (*rpc.GetTokenLargestAccountsResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetTokenLargestAccountsResult, error) {
+		return c.GetTokenLargestAccounts(ctx, mint, commitment)
+	})
+}
+
+func (f *FailoverClient) GetMinimumBalanceForRentExemption(ctx context.Context, dataSize uint64, commitment rpc.CommitmentType) (uint64, error) {
+	return failover(ctx, f, func(c RPCClient) (uint64, error) {
+		return c.GetMinimumBalanceForRentExemption(ctx, dataSize, commitment)
+	})
+}
+
+func (f *FailoverClient) GetRecentPrioritizationFees(ctx context.Context, accounts solana.PublicKeySlice) ([]rpc.PriorizationFeeResult, error) {
+	return failover(ctx, f, func(c RPCClient) ([]rpc.PriorizationFeeResult, error) {
+		return c.GetRecentPrioritizationFees(ctx, accounts)
+	})
+}
+
+func (f *FailoverClient) GetLatestBlockhash(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetLatestBlockhashResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetLatestBlockhashResult, error) {
+		return c.GetLatestBlockhash(ctx, commitment)
+	})
+}
+
+func (f *FailoverClient) SendTransactionWithOpts(ctx context.Context, tx *solana.Transaction, opts rpc.TransactionOpts) (solana.Signature, error) {
+	return failover(ctx, f, func(c RPCClient) (solana.Signature, error) {
+		return c.SendTransactionWithOpts(ctx, tx, opts)
+	})
+}
+
+func (f *FailoverClient) GetSignatureStatuses(ctx context.Context, searchTransactionHistory bool, signatures ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetSignatureStatusesResult, error) {
+		return c.GetSignatureStatuses(ctx, searchTransactionHistory, signatures...)
+	})
+}
+
+func (f *FailoverClient) GetTransaction(ctx context.Context, sig solana.Signature, opts *rpc.GetTransactionOpts) (*rpc.GetTransactionResult, error) {
+	return failover(ctx, f, func(c RPCClient) (*rpc.GetTransactionResult, error) {
+		return c.GetTransaction(ctx, sig, opts)
+	})
+}
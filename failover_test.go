@@ -0,0 +1,39 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+func TestFailoverClient(t *testing.T) {
+	account := solana.NewWallet().PublicKey()
+	tests := []struct {
+		name      string
+		firstErr  error
+		wantErr   error
+		wantCalls int
+	}{
+		{"first succeeds", nil, nil, 0},
+		{"rate limited", jsonrpc.NewHTTPError(429, errors.New("too many requests")), nil, 1},
+		{"account not found", rpc.ErrNotFound, rpc.ErrNotFound, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &mockRPCClient{accounts: map[solana.PublicKey][]byte{account: {1}}, err: tt.firstErr}
+			second := &mockRPCClient{accounts: map[solana.PublicKey][]byte{account: {2}}}
+			client := NewFailoverClient(first, second)
+			_, err := client.GetAccountInfoWithOpts(context.Background(), account, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetAccountInfoWithOpts() error = %v, want %v", err, tt.wantErr)
+			}
+			if second.calls != tt.wantCalls {
+				t.Fatalf("second endpoint called %d times, want %d", second.calls, tt.wantCalls)
+			}
+		})
+	}
+}
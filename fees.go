@@ -0,0 +1,185 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	cb "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// computeUnitLimit is the compute unit limit of the transactions, the same as the default of pump.fun.
+const computeUnitLimit = 250000
+
+// Fees paid for every transaction, on top of the priority fee.
+const (
+	// baseFeePerSignature is the fee paid for each signature of a transaction, in lamports.
+	baseFeePerSignature = 5000
+	// tokenAccountSize is the size of an SPL token account, used to compute its rent.
+	tokenAccountSize = 165
+)
+
+// Default compute unit prices, in micro-lamports, of the buy and sell transactions.
+const (
+	defaultBuyComputeUnitPrice    = 100000
+	defaultSellComputeUnitPrice   = 10000
+	defaultCreateComputeUnitPrice = 100000
+)
+
+// PriorityFeeProvider returns the compute unit price, in micro-lamports, of the next transaction.
+type PriorityFeeProvider func(ctx context.Context) (uint64, error)
+
+// getComputeUnitPrice returns the compute unit price of the transaction,
+// from the PriorityFeeProvider of the options if set, from fallback otherwise, scaled by the priority fee multiplier.
+func getComputeUnitPrice(ctx context.Context, o *options, fallback func() (uint64, error)) (uint64, error) {
+	get := fallback
+	if o.priorityFeeProvider != nil {
+		get = func() (uint64, error) {
+			return o.priorityFeeProvider(ctx)
+		}
+	}
+	computeUnitPrice, err := get()
+	if err != nil {
+		return 0, err
+	}
+	if o.priorityFeeMultiplier > 0 {
+		computeUnitPrice = uint64(math.Ceil(float64(computeUnitPrice) * o.priorityFeeMultiplier))
+	}
+	o.logger.Debug("chose compute unit price", "computeUnitPrice", computeUnitPrice, "provider", o.priorityFeeProvider != nil)
+	return computeUnitPrice, nil
+}
+
+// computeBudgetInstructions returns the instructions setting the compute unit limit and price of a transaction,
+// none if the options omit them.
+func computeBudgetInstructions(computeUnitPrice uint64, o *options) []solana.Instruction {
+	if o.withoutComputeBudget {
+		return nil
+	}
+	return []solana.Instruction{
+		cb.NewSetComputeUnitLimitInstruction(computeUnitLimit).Build(),
+		cb.NewSetComputeUnitPriceInstruction(computeUnitPrice).Build(),
+	}
+}
+
+// getRecentComputeUnitPrice returns the median of the recent prioritization fees paid for the accounts used by pump.fun,
+// or the default compute unit price of the options if there are none.
+func getRecentComputeUnitPrice(ctx context.Context, rpcClient RPCClient, user solana.PublicKey, o *options) (uint64, error) {
+	out, err := rpcClient.GetRecentPrioritizationFees(ctx, solana.PublicKeySlice{user, pump.ProgramID, pumpFunMintAuthority, globalPumpFunAddress, pumpFunTokenMetadataProgram, pumpFunSystemProgram, token.ProgramID, associatedtokenaccount.ProgramID, pumpFunRentSysvar, pumpFunEventAuthority})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recent prioritization fees: %w", err)
+	}
+	if len(out) == 0 {
+		o.logger.Warn("no recent prioritization fees, using the default compute unit price", "computeUnitPrice", o.defaultComputeUnitPrice)
+		return o.defaultComputeUnitPrice, nil
+	}
+	return medianPrioritizationFee(out), nil
+}
+
+// medianPrioritizationFee returns the median of the prioritization fees, which must not be empty.
+func medianPrioritizationFee(out []rpc.PriorizationFeeResult) uint64 {
+	fees := make([]uint64, len(out))
+	for i, fee := range out {
+		fees[i] = fee.PrioritizationFee
+	}
+	slices.Sort(fees)
+	return fees[len(fees)/2]
+}
+
+// CostBreakdown details everything debited from the user's wallet by a buy.
+type CostBreakdown struct {
+	// BuyAmount is the SOL spent on tokens.
+	BuyAmount Lamports
+	// AtaRent is the rent of the associated token account, 0 if it already exists.
+	AtaRent Lamports
+	// PriorityFee is the compute unit limit times the compute unit price.
+	PriorityFee Lamports
+	// BaseFee is the fee paid for the signature of the transaction.
+	BaseFee Lamports
+	// Total is the sum of all the above.
+	Total Lamports
+}
+
+// EstimateBuyCost estimates the total amount of lamports debited by BuyToken with the same parameters.
+func EstimateBuyCost(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, user solana.PublicKey, solAmount Lamports, opts ...Option) (*CostBreakdown, error) {
+	o := newOptions(opts)
+	computeUnitPrice, err := getComputeUnitPrice(ctx, o, func() (uint64, error) {
+		return defaultBuyComputeUnitPrice, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute unit price: %w", err)
+	}
+	return estimateBuyCost(ctx, rpcClient, mint, user, solAmount, computeUnitPrice, o)
+}
+
+func estimateBuyCost(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, user solana.PublicKey, solAmount Lamports, computeUnitPrice uint64, o *options) (*CostBreakdown, error) {
+	if o.maxSolCost > 0 {
+		solAmount = min(solAmount, o.maxSolCost)
+	}
+	ata, err := findAssociatedTokenAddress(user, mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	shouldCreateATA, err := shouldCreateAta(ctx, rpcClient, ata)
+	if err != nil {
+		return nil, fmt.Errorf("can't check if we should create ATA: %w", err)
+	}
+	var ataRent uint64
+	if shouldCreateATA {
+		ataRent, err = rpcClient.GetMinimumBalanceForRentExemption(ctx, tokenAccountSize, rpc.CommitmentConfirmed)
+		if err != nil {
+			return nil, fmt.Errorf("can't get rent of associated token account: %w", err)
+		}
+	}
+	cost := &CostBreakdown{
+		BuyAmount:   solAmount,
+		AtaRent:     Lamports(ataRent),
+		PriorityFee: priorityFee(computeUnitPrice),
+		BaseFee:     baseFeePerSignature,
+	}
+	cost.Total = cost.BuyAmount + cost.AtaRent + cost.PriorityFee + cost.BaseFee
+	return cost, nil
+}
+
+// GetSolBalance returns the SOL balance of the wallet, in lamports.
+func GetSolBalance(ctx context.Context, rpcClient RPCClient, wallet solana.PublicKey) (Lamports, error) {
+	balance, err := rpcClient.GetBalance(ctx, wallet, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, fmt.Errorf("can't get balance of %s: %w", wallet, err)
+	}
+	return Lamports(balance.Value), nil
+}
+
+// checkBalance returns an *InsufficientFundsError if the wallet holds less than the required lamports.
+func checkBalance(ctx context.Context, rpcClient RPCClient, wallet solana.PublicKey, required Lamports) error {
+	available, err := GetSolBalance(ctx, rpcClient, wallet)
+	if err != nil {
+		return err
+	}
+	if available < required {
+		return &InsufficientFundsError{Required: required, Available: available}
+	}
+	return nil
+}
+
+// maxComputeUnitPrice returns the highest compute unit price keeping the fees of a transaction,
+// using the whole compute unit limit, under the fraction of the amount.
+func maxComputeUnitPrice(amount Lamports, fraction float64) uint64 {
+	maxFee := fraction*float64(amount) - baseFeePerSignature
+	if maxFee <= 0 {
+		return 0
+	}
+	// The compute unit price is in micro-lamports.
+	return uint64(maxFee * 1000000 / computeUnitLimit)
+}
+
+// priorityFee returns the priority fee, in lamports, of a transaction using the whole compute unit limit.
+func priorityFee(computeUnitPrice uint64) Lamports {
+	// The compute unit price is in micro-lamports.
+	return Lamports(computeUnitLimit * computeUnitPrice / 1000000)
+}
@@ -0,0 +1,90 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestGetRecentComputeUnitPrice(t *testing.T) {
+	tests := []struct {
+		name string
+		fees []uint64
+		opts []Option
+		want uint64
+	}{
+		{"no fees", nil, nil, defaultCreateComputeUnitPrice},
+		{"no fees with default", nil, []Option{WithDefaultComputeUnitPrice(42)}, 42},
+		{"median", []uint64{300, 100, 200}, nil, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient := &mockRPCClient{prioritizationFees: []rpc.PriorizationFeeResult{}}
+			for _, fee := range tt.fees {
+				rpcClient.prioritizationFees = append(rpcClient.prioritizationFees, rpc.PriorizationFeeResult{PrioritizationFee: fee})
+			}
+			got, err := getRecentComputeUnitPrice(context.Background(), rpcClient, solana.NewWallet().PublicKey(), newOptions(tt.opts))
+			if err != nil {
+				t.Fatalf("getRecentComputeUnitPrice() error = %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("getRecentComputeUnitPrice() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetComputeUnitPrice(t *testing.T) {
+	provider := func(context.Context) (uint64, error) {
+		return 1001, nil
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		want uint64
+	}{
+		{"fallback", nil, 100},
+		{"provider", []Option{WithPriorityFeeProvider(provider)}, 1001},
+		{"scaled fallback", []Option{WithPriorityFeeMultiplier(1.5)}, 150},
+		{"scaled provider, rounded up", []Option{WithPriorityFeeProvider(provider), WithPriorityFeeMultiplier(1.5)}, 1502},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getComputeUnitPrice(context.Background(), newOptions(tt.opts), func() (uint64, error) {
+				return 100, nil
+			})
+			if err != nil {
+				t.Fatalf("getComputeUnitPrice() error = %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("getComputeUnitPrice() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxComputeUnitPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Lamports
+		fraction float64
+		want     uint64
+	}{
+		// 10% of 0.01 SOL leaves 995000 lamports of priority fee, over 250000 compute units.
+		{"10%", 10000000, 0.1, 3980000},
+		{"less than the base fee", 10000, 0.1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maxComputeUnitPrice(tt.amount, tt.fraction)
+			if got != tt.want {
+				t.Fatalf("maxComputeUnitPrice() = %d, want %d", got, tt.want)
+			}
+			if fee := priorityFee(got) + baseFeePerSignature; got > 0 && float64(fee) > tt.fraction*float64(tt.amount) {
+				t.Fatalf("fees of %d lamports over %v of %d", fee, tt.fraction, tt.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// defaultFeeBasisPoints is the fee charged by pump.fun on every trade, used when the global account can't be fetched.
+const defaultFeeBasisPoints = 100
+
+// globalCacheTTL is how long the global account is cached, so that long-running processes
+// pick up the changes of its parameters, e.g. of the fee.
+var globalCacheTTL = 5 * time.Minute
+
+// cachedGlobalAccount is the cached global account, and when it was fetched.
+type cachedGlobalAccount struct {
+	global    *pump.Global
+	fetchedAt time.Time
+}
+
+// cachedGlobal caches the global account, as its parameters rarely change.
+var cachedGlobal atomic.Pointer[cachedGlobalAccount]
+
+// getGlobal returns the global account, fetching it again once the cached one is older than globalCacheTTL.
+func getGlobal(ctx context.Context, rpcClient RPCClient) (*pump.Global, error) {
+	if cached := cachedGlobal.Load(); cached != nil && time.Since(cached.fetchedAt) < globalCacheTTL {
+		return cached.global, nil
+	}
+	return RefreshGlobal(ctx, rpcClient)
+}
+
+// RefreshGlobal fetches the global account, and caches it in place of the one the quotes use,
+// e.g. right after pump.fun changed its fee. The cached global account is otherwise refreshed every 5 minutes.
+func RefreshGlobal(ctx context.Context, rpcClient RPCClient) (*pump.Global, error) {
+	global, err := fetchGlobal(ctx, rpcClient)
+	if err != nil {
+		return nil, err
+	}
+	cachedGlobal.Store(&cachedGlobalAccount{global: global, fetchedAt: time.Now()})
+	return global, nil
+}
+
+// getFeeBasisPoints returns the fee charged by pump.fun on trades, from the options if set,
+// from the global account otherwise, or the default fee if it can't be fetched.
+// It returns the error of the context if it is done, instead of falling back.
+func getFeeBasisPoints(ctx context.Context, rpcClient RPCClient, o *options) (uint64, error) {
+	if o.feeBasisPoints != nil {
+		return *o.feeBasisPoints, nil
+	}
+	global, err := getGlobal(ctx, rpcClient)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("can't fetch global account: %w", ctx.Err())
+		}
+		o.logger.Warn("can't fetch global account, using the default fee", "feeBasisPoints", defaultFeeBasisPoints, "error", err)
+		return defaultFeeBasisPoints, nil
+	}
+	return global.FeeBasisPoints, nil
+}
+
+// fetchGlobal fetches the pump.fun global account, holding the parameters of the program.
+func fetchGlobal(ctx context.Context, rpcClient RPCClient) (*pump.Global, error) {
+	accountInfo, err := rpcClient.GetAccountInfoWithOpts(ctx, globalPumpFunAddress, &rpc.GetAccountInfoOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentConfirmed})
+	if err != nil || accountInfo.Value == nil {
+		return nil, fmt.Errorf("failed to get global account info: %w", err)
+	}
+	var global pump.Global
+	if err := bin.NewBinDecoder(accountInfo.Value.Data.GetBinary()).Decode(&global); err != nil {
+		return nil, fmt.Errorf("failed to decode global account: %w", err)
+	}
+	return &global, nil
+}
+
+// initialBondingCurve returns the bonding curve every new token starts with.
+func initialBondingCurve(global *pump.Global) *BondingCurveData {
+	return &BondingCurveData{
+		RealTokenReserves:    new(big.Int).SetUint64(global.InitialRealTokenReserves),
+		VirtualTokenReserves: new(big.Int).SetUint64(global.InitialVirtualTokenReserves),
+		VirtualSolReserves:   new(big.Int).SetUint64(global.InitialVirtualSolReserves),
+		RealSolReserves:      new(big.Int),
+		TokenTotalSupply:     new(big.Int).SetUint64(global.TokenTotalSupply),
+	}
+}
@@ -0,0 +1,74 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestGetGlobal(t *testing.T) {
+	defer cachedGlobal.Store(nil)
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		globalPumpFunAddress: encodeAccount(t, pump.Global{FeeBasisPoints: 100}),
+	}}
+	fee := func() uint64 {
+		global, err := getGlobal(context.Background(), rpcClient)
+		if err != nil {
+			t.Fatalf("getGlobal() error = %s", err)
+		}
+		return global.FeeBasisPoints
+	}
+	if got := fee(); got != 100 {
+		t.Fatalf("getGlobal() fee = %d, want 100", got)
+	}
+	// pump.fun raised its fee.
+	rpcClient.accounts[globalPumpFunAddress] = encodeAccount(t, pump.Global{FeeBasisPoints: 150})
+	if got := fee(); got != 100 || rpcClient.calls != 1 {
+		t.Fatalf("getGlobal() fee = %d after %d RPC calls, want the cached 100 after 1", got, rpcClient.calls)
+	}
+	if _, err := RefreshGlobal(context.Background(), rpcClient); err != nil {
+		t.Fatalf("RefreshGlobal() error = %s", err)
+	}
+	if got := fee(); got != 150 {
+		t.Fatalf("getGlobal() fee = %d after RefreshGlobal, want 150", got)
+	}
+	// The cached global account expires.
+	rpcClient.accounts[globalPumpFunAddress] = encodeAccount(t, pump.Global{FeeBasisPoints: 200})
+	cachedGlobal.Load().fetchedAt = time.Now().Add(-globalCacheTTL)
+	if got := fee(); got != 200 {
+		t.Fatalf("getGlobal() fee = %d after the TTL, want 200", got)
+	}
+}
+
+func TestGetFeeBasisPoints(t *testing.T) {
+	defer cachedGlobal.Store(nil)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		opts    []Option
+		want    uint64
+		wantErr error
+	}{
+		{"options", context.Background(), []Option{WithFeeBasisPoints(95)}, 95, nil},
+		{"default fee", context.Background(), nil, defaultFeeBasisPoints, nil},
+		{"canceled", canceled, nil, 0, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient := &mockRPCClient{err: errors.New("rpc unavailable")}
+			got, err := getFeeBasisPoints(tt.ctx, rpcClient, newOptions(tt.opts))
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("getFeeBasisPoints() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("getFeeBasisPoints() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,9 @@ require (
 	github.com/gagliardetto/gofuzz v1.2.2
 	github.com/gagliardetto/solana-go v1.11.0
 	github.com/gagliardetto/treeout v0.1.4
+	github.com/gorilla/websocket v1.4.2
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/sync v0.16.0
 )
 
 require (
@@ -19,7 +21,6 @@ require (
 	github.com/fatih/color v1.9.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/rpc v1.2.0 // indirect
-	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/compress v1.13.6 // indirect
 	github.com/logrusorgru/aurora v2.0.3+incompatible // indirect
@@ -0,0 +1,122 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// TokenHolder is a token account holding a pump.fun token.
+type TokenHolder struct {
+	// Owner is the wallet owning the token account.
+	Owner solana.PublicKey
+	// TokenAccount is the address of the token account.
+	TokenAccount solana.PublicKey
+	Amount       TokenAmount
+	// IsBondingCurve is true for the associated bonding curve, i.e. the tokens not sold yet.
+	IsBondingCurve bool
+}
+
+// GetTokenHolders returns the largest holders of the mint, up to 20, from the largest to the smallest.
+// The bonding curve holds the tokens not sold yet, and is flagged as such to tell it apart from the external holders.
+func GetTokenHolders(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, opts ...Option) ([]TokenHolder, error) {
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, newOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	largest, err := rpcClient.GetTokenLargestAccounts(ctx, mint, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("can't get largest token accounts: %w", err)
+	}
+	tokenAccounts := make([]solana.PublicKey, len(largest.Value))
+	for i, account := range largest.Value {
+		tokenAccounts[i] = account.Address
+	}
+	// The largest accounts are token accounts, fetch them to get their owners.
+	accounts, err := rpcClient.GetMultipleAccountsWithOpts(ctx, tokenAccounts, &rpc.GetMultipleAccountsOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, fmt.Errorf("can't get token accounts: %w", err)
+	}
+	holders := make([]TokenHolder, 0, len(largest.Value))
+	for i, account := range largest.Value {
+		amount, err := strconv.ParseUint(account.Amount, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't convert token amount to integer: %w", err)
+		}
+		holder := TokenHolder{
+			TokenAccount:   account.Address,
+			Amount:         TokenAmount(amount),
+			IsBondingCurve: account.Address == bondingCurveData.AssociatedBondingCurve,
+		}
+		if i < len(accounts.Value) && accounts.Value[i] != nil {
+			var tokenAccount token.Account
+			if err := bin.NewBinDecoder(accounts.Value[i].Data.GetBinary()).Decode(&tokenAccount); err != nil {
+				return nil, fmt.Errorf("can't decode token account %s: %w", account.Address, err)
+			}
+			holder.Owner = tokenAccount.Owner
+		}
+		holders = append(holders, holder)
+	}
+	return holders, nil
+}
+
+// TokenSupply is the split of the supply of a pump.fun token between the bonding curve and the holders.
+type TokenSupply struct {
+	// Total is the supply of the mint.
+	Total TokenAmount
+	// BondingCurveTotal is the total supply recorded by the bonding curve.
+	BondingCurveTotal TokenAmount
+	// InBondingCurve is held by the associated bonding curve: the tokens left to buy, and the ones kept for the migration.
+	InBondingCurve TokenAmount
+	// RealTokenReserves is the part of InBondingCurve left to buy.
+	RealTokenReserves TokenAmount
+	// Circulating is held outside of the bonding curve.
+	Circulating TokenAmount
+}
+
+// GetTokenSupply returns the supply of the mint, and how much of it is held by the bonding curve or circulating,
+// read from the mint, the bonding curve and the associated bonding curve accounts in a single RPC call.
+func GetTokenSupply(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey, opts ...Option) (*TokenSupply, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	keys := []solana.PublicKey{mint, bondingCurveData.BondingCurve, bondingCurveData.AssociatedBondingCurve}
+	accounts, err := rpcClient.GetMultipleAccountsWithOpts(ctx, keys, &rpc.GetMultipleAccountsOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, fmt.Errorf("can't get supply accounts: %w", err)
+	}
+	if len(accounts.Value) != len(keys) {
+		return nil, fmt.Errorf("got %d supply accounts, want %d", len(accounts.Value), len(keys))
+	}
+	for i, account := range accounts.Value {
+		if account == nil {
+			return nil, fmt.Errorf("account %s not found", keys[i])
+		}
+	}
+	var mintAccount token.Mint
+	if err := bin.NewBinDecoder(accounts.Value[0].Data.GetBinary()).Decode(&mintAccount); err != nil {
+		return nil, fmt.Errorf("can't decode mint %s: %w", mint, err)
+	}
+	bondingCurve, err := decodeBondingCurve(accounts.Value[1].Data.GetBinary())
+	if err != nil {
+		return nil, err
+	}
+	var associatedBondingCurve token.Account
+	if err := bin.NewBinDecoder(accounts.Value[2].Data.GetBinary()).Decode(&associatedBondingCurve); err != nil {
+		return nil, fmt.Errorf("can't decode associated bonding curve: %w", err)
+	}
+	return &TokenSupply{
+		Total:             TokenAmount(mintAccount.Supply),
+		BondingCurveTotal: TokenAmount(bondingCurve.TokenTotalSupply.Uint64()),
+		InBondingCurve:    TokenAmount(associatedBondingCurve.Amount),
+		RealTokenReserves: TokenAmount(bondingCurve.RealTokenReserves.Uint64()),
+		Circulating:       TokenAmount(mintAccount.Supply - min(associatedBondingCurve.Amount, mintAccount.Supply)),
+	}, nil
+}
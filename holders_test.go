@@ -0,0 +1,53 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// encodeAccount encodes a token program account as it is stored on-chain.
+func encodeAccount(t *testing.T, account interface{}) []byte {
+	var buf bytes.Buffer
+	if err := bin.NewBinEncoder(&buf).Encode(account); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGetTokenSupply(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 100M tokens were sold, out of the 793.1M for sale.
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		mint:                                    encodeAccount(t, token.Mint{Supply: 1000000000000000, Decimals: 6, IsInitialized: true}),
+		bondingCurveData.BondingCurve:           bondingCurveAccountData(973000000000000, 33083247687, 693100000000000, 3083247687, 1000000000000000, false),
+		bondingCurveData.AssociatedBondingCurve: encodeAccount(t, token.Account{Mint: mint, Owner: bondingCurveData.BondingCurve, Amount: 900000000000000}),
+	}}
+	got, err := GetTokenSupply(context.Background(), rpcClient, mint)
+	if err != nil {
+		t.Fatalf("GetTokenSupply() error = %s", err)
+	}
+	want := TokenSupply{
+		Total:             1000000000000000,
+		BondingCurveTotal: 1000000000000000,
+		InBondingCurve:    900000000000000,
+		RealTokenReserves: 693100000000000,
+		Circulating:       100000000000000,
+	}
+	if *got != want {
+		t.Fatalf("GetTokenSupply() = %+v, want %+v", *got, want)
+	}
+
+	delete(rpcClient.accounts, bondingCurveData.BondingCurve)
+	if _, err := GetTokenSupply(context.Background(), rpcClient, mint); err == nil {
+		t.Fatal("GetTokenSupply() without bonding curve, want error")
+	}
+}
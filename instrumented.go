@@ -0,0 +1,106 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ObserveFunc is called after each RPC call, with the name of the RPC method, its duration and its error, if any.
+type ObserveFunc func(op string, dur time.Duration, err error)
+
+// InstrumentedClient is an RPCClient calling ObserveFunc after each call of the wrapped client,
+// e.g. to record the latency and the success rate of each RPC method in Prometheus.
+type InstrumentedClient struct {
+	client  RPCClient
+	observe ObserveFunc
+}
+
+var _ RPCClient = (*InstrumentedClient)(nil)
+
+// NewInstrumentedClient returns an InstrumentedClient wrapping the client.
+func NewInstrumentedClient(client RPCClient, observe ObserveFunc) *InstrumentedClient {
+	return &InstrumentedClient{client: client, observe: observe}
+}
+
+// instrument calls call, and observes its duration and error as op.
+func instrument[T any](i *InstrumentedClient, op string, call func() (T, error)) (T, error) {
+	start := time.Now()
+	out, err := call()
+	i.observe(op, time.Since(start), err)
+	return out, err
+}
+
+func (i *InstrumentedClient) GetAccountInfo(ctx context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	return instrument(i, "getAccountInfo", func() (*rpc.GetAccountInfoResult, error) {
+		return i.client.GetAccountInfo(ctx, account)
+	})
+}
+
+func (i *InstrumentedClient) GetAccountInfoWithOpts(ctx context.Context, account solana.PublicKey, opts *rpc.GetAccountInfoOpts) (*rpc.GetAccountInfoResult, error) {
+	return instrument(i, "getAccountInfo", func() (*rpc.GetAccountInfoResult, error) {
+		return i.client.GetAccountInfoWithOpts(ctx, account, opts)
+	})
+}
+
+func (i *InstrumentedClient) GetMultipleAccountsWithOpts(ctx context.Context, accounts []solana.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error) {
+	return instrument(i, "getMultipleAccounts", func() (*rpc.GetMultipleAccountsResult, error) {
+		return i.client.GetMultipleAccountsWithOpts(ctx, accounts, opts)
+	})
+}
+
+func (i *InstrumentedClient) GetBalance(ctx context.Context, account solana.PublicKey, commitment rpc.CommitmentType) (*rpc.GetBalanceResult, error) {
+	return instrument(i, "getBalance", func() (*rpc.GetBalanceResult, error) {
+		return i.client.GetBalance(ctx, account, commitment)
+	})
+}
+
+func (i *InstrumentedClient) GetTokenAccountBalance(ctx context.Context, account solana.PublicKey, commitment rpc.CommitmentType) (*rpc.GetTokenAccountBalanceResult, error) {
+	return instrument(i, "getTokenAccountBalance", func() (*rpc.GetTokenAccountBalanceResult, error) {
+		return i.client.GetTokenAccountBalance(ctx, account, commitment)
+	})
+}
+
+func (i *InstrumentedClient) GetTokenLargestAccounts(ctx context.Context, mint solana.PublicKey, commitment rpc.CommitmentType) (*rpc.GetTokenLargestAccountsResult, error) {
+	return instrument(i, "getTokenLargestAccounts", func() (*rpc.GetTokenLargestAccountsResult, error) {
+		return i.client.GetTokenLargestAccounts(ctx, mint, commitment)
+	})
+}
+
+func (i *InstrumentedClient) GetMinimumBalanceForRentExemption(ctx context.Context, dataSize uint64, commitment rpc.CommitmentType) (uint64, error) {
+	return instrument(i, "getMinimumBalanceForRentExemption", func() (uint64, error) {
+		return i.client.GetMinimumBalanceForRentExemption(ctx, dataSize, commitment)
+	})
+}
+
+func (i *InstrumentedClient) GetRecentPrioritizationFees(ctx context.Context, accounts solana.PublicKeySlice) ([]rpc.PriorizationFeeResult, error) {
+	return instrument(i, "getRecentPrioritizationFees", func() ([]rpc.PriorizationFeeResult, error) {
+		return i.client.GetRecentPrioritizationFees(ctx, accounts)
+	})
+}
+
+func (i *InstrumentedClient) GetLatestBlockhash(ctx context.Context, commitment rpc.CommitmentType) (*rpc.GetLatestBlockhashResult, error) {
+	return instrument(i, "getLatestBlockhash", func() (*rpc.GetLatestBlockhashResult, error) {
+		return i.client.GetLatestBlockhash(ctx, commitment)
+	})
+}
+
+func (i *InstrumentedClient) SendTransactionWithOpts(ctx context.Context, tx *solana.Transaction, opts rpc.TransactionOpts) (solana.Signature, error) {
+	return instrument(i, "sendTransaction", func() (solana.Signature, error) {
+		return i.client.SendTransactionWithOpts(ctx, tx, opts)
+	})
+}
+
+func (i *InstrumentedClient) GetSignatureStatuses(ctx context.Context, searchTransactionHistory bool, signatures ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	return instrument(i, "getSignatureStatuses", func() (*rpc.GetSignatureStatusesResult, error) {
+		return i.client.GetSignatureStatuses(ctx, searchTransactionHistory, signatures...)
+	})
+}
+
+func (i *InstrumentedClient) GetTransaction(ctx context.Context, sig solana.Signature, opts *rpc.GetTransactionOpts) (*rpc.GetTransactionResult, error) {
+	return instrument(i, "getTransaction", func() (*rpc.GetTransactionResult, error) {
+		return i.client.GetTransaction(ctx, sig, opts)
+	})
+}
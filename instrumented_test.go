@@ -0,0 +1,30 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestInstrumentedClient(t *testing.T) {
+	account := solana.NewWallet().PublicKey()
+	var (
+		ops  []string
+		errs []error
+	)
+	client := NewInstrumentedClient(&mockRPCClient{}, func(op string, dur time.Duration, err error) {
+		ops = append(ops, op)
+		errs = append(errs, err)
+	})
+	_, err := client.GetAccountInfoWithOpts(context.Background(), account, nil)
+	if !errors.Is(err, rpc.ErrNotFound) {
+		t.Fatalf("GetAccountInfoWithOpts() error = %v, want %v", err, rpc.ErrNotFound)
+	}
+	if len(ops) != 1 || ops[0] != "getAccountInfo" || !errors.Is(errs[0], rpc.ErrNotFound) {
+		t.Fatalf("observed %v %v, want [getAccountInfo] [%v]", ops, errs, rpc.ErrNotFound)
+	}
+}
@@ -0,0 +1,16 @@
+package pumpdotfunsdk
+
+// Logger logs the key steps of the SDK, with a message and alternating keys and values.
+// *slog.Logger implements it, so it can be passed as is.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+}
+
+// noopLogger is the default Logger, logging nothing.
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...interface{}) {}
+func (noopLogger) Info(string, ...interface{})  {}
+func (noopLogger) Warn(string, ...interface{})  {}
@@ -0,0 +1,92 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// tokenMetadata is the beginning of a Metaplex metadata account, the rest is ignored.
+type tokenMetadata struct {
+	Key             uint8
+	UpdateAuthority solana.PublicKey
+	Mint            solana.PublicKey
+	Name            string
+	Symbol          string
+	Uri             string
+}
+
+// offChainMetadata is the JSON document the URI of the metadata points to.
+type offChainMetadata struct {
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	Twitter     string `json:"twitter"`
+	Telegram    string `json:"telegram"`
+	Website     string `json:"website"`
+}
+
+// VerifyTokenMetadata checks that the on-chain metadata of the mint, and the document its URI points to,
+// have the name, symbol and description expected, e.g. to catch IPFS propagation failures after CreateToken.
+// It returns an error if the metadata can't be fetched, and false if it doesn't match.
+func VerifyTokenMetadata(ctx context.Context, rpcClient RPCClient, httpClient *http.Client, mint solana.PublicKey, expected CreateTokenMetadataRequest) (bool, error) {
+	onChain, err := fetchTokenMetadata(ctx, rpcClient, mint)
+	if err != nil {
+		return false, err
+	}
+	if onChain.Name != expected.Name || onChain.Symbol != expected.Symbol {
+		return false, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, onChain.Uri, nil)
+	if err != nil {
+		return false, fmt.Errorf("can't create request of metadata URI: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("can't get metadata URI: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("can't get metadata URI: status code %d", resp.StatusCode)
+	}
+	var offChain offChainMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&offChain); err != nil {
+		return false, fmt.Errorf("can't decode metadata URI: %w", err)
+	}
+	return offChain.Name == expected.Name && offChain.Symbol == expected.Symbol && offChain.Description == expected.Description, nil
+}
+
+// findTokenMetadataAddress returns the address of the metadata account of the mint, owned by the token metadata program
+// of the network addresses.
+func findTokenMetadataAddress(mint solana.PublicKey) (solana.PublicKey, error) {
+	address, _, err := solana.FindProgramAddress([][]byte{[]byte("metadata"), pumpFunTokenMetadataProgram[:], mint[:]}, pumpFunTokenMetadataProgram)
+	return address, err
+}
+
+// fetchTokenMetadata fetches the Metaplex metadata account of the mint.
+func fetchTokenMetadata(ctx context.Context, rpcClient RPCClient, mint solana.PublicKey) (*tokenMetadata, error) {
+	address, err := findTokenMetadataAddress(mint)
+	if err != nil {
+		return nil, fmt.Errorf("can't find token metadata address: %w", err)
+	}
+	accountInfo, err := rpcClient.GetAccountInfoWithOpts(ctx, address, &rpc.GetAccountInfoOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return nil, fmt.Errorf("can't get token metadata account: %w", err)
+	}
+	var metadata tokenMetadata
+	if err := bin.NewBorshDecoder(accountInfo.Value.Data.GetBinary()).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("can't decode token metadata account: %w", err)
+	}
+	// The strings are padded with zeros to their maximum length.
+	metadata.Name = strings.TrimRight(metadata.Name, "\x00")
+	metadata.Symbol = strings.TrimRight(metadata.Symbol, "\x00")
+	metadata.Uri = strings.TrimRight(metadata.Uri, "\x00")
+	return &metadata, nil
+}
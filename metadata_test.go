@@ -0,0 +1,57 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestVerifyTokenMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"Test","symbol":"TEST","description":"A test token"}`)
+	}))
+	defer server.Close()
+	mint := solana.NewWallet().PublicKey()
+	address, _, err := solana.FindTokenMetadataAddress(mint)
+	if err != nil {
+		t.Fatalf("can't find token metadata address: %s", err)
+	}
+	var buf bytes.Buffer
+	err = bin.NewBorshEncoder(&buf).Encode(tokenMetadata{
+		Key:    4,
+		Mint:   mint,
+		Name:   "Test" + string(make([]byte, 28)),
+		Symbol: "TEST" + string(make([]byte, 6)),
+		Uri:    server.URL,
+	})
+	if err != nil {
+		t.Fatalf("can't encode token metadata: %s", err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{address: buf.Bytes()}}
+	tests := []struct {
+		name     string
+		expected CreateTokenMetadataRequest
+		want     bool
+	}{
+		{"match", CreateTokenMetadataRequest{Name: "Test", Symbol: "TEST", Description: "A test token"}, true},
+		{"other symbol", CreateTokenMetadataRequest{Name: "Test", Symbol: "TST", Description: "A test token"}, false},
+		{"other description", CreateTokenMetadataRequest{Name: "Test", Symbol: "TEST", Description: "Another token"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyTokenMetadata(context.Background(), rpcClient, server.Client(), mint, tt.expected)
+			if err != nil {
+				t.Fatalf("VerifyTokenMetadata() error = %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("VerifyTokenMetadata() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
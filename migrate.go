@@ -0,0 +1,46 @@
+package pumpdotfunsdk
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// The pump.fun program has no account extension instruction. Besides Create, Buy and Sell,
+// wrapped by CreateToken, BuyToken and SellToken, it has three admin instructions:
+//   - Withdraw, the migration of the liquidity of a complete bonding curve, wrapped by WithdrawInstruction.
+//   - Initialize and SetParams, setting the global account, only used once by the pump.fun admin,
+//     and available as is from the pump package.
+
+// WithdrawInstruction returns the instruction withdrawing the liquidity of the complete bonding curve of the mint,
+// to migrate it. Only the authority of the global account can sign it, anybody else gets ErrNotAuthorized.
+// The associated token account of the authority must exist.
+func WithdrawInstruction(authority solana.PublicKey, mint solana.PublicKey, opts ...Option) (solana.Instruction, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	ata, err := findAssociatedTokenAddress(authority, mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	withdraw, err := pump.NewWithdrawInstruction(
+		globalPumpFunAddress,
+		mint,
+		bondingCurveData.BondingCurve,
+		bondingCurveData.AssociatedBondingCurve,
+		ata,
+		authority,
+		pumpFunSystemProgram,
+		o.getTokenProgram(),
+		pumpFunRentSysvar,
+		pumpFunEventAuthority,
+		pump.ProgramID,
+	).ValidateAndBuild()
+	if err != nil {
+		return nil, fmt.Errorf("can't validate and build withdraw instruction: %w", err)
+	}
+	return withdraw, nil
+}
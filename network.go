@@ -0,0 +1,128 @@
+package pumpdotfunsdk
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// NetworkAddresses are the static accounts passed to the pump.fun instructions, which depend on the network
+// it's deployed on, or on the fork of the program. Besides the fee recipient and the programs and sysvars it relies on,
+// they are all derived from the program ID.
+type NetworkAddresses struct {
+	ProgramID solana.PublicKey
+	// Global is the global account, holding the parameters of the program.
+	Global         solana.PublicKey
+	MintAuthority  solana.PublicKey
+	EventAuthority solana.PublicKey
+	// FeeRecipient is the account receiving the fees, which must be one of the global account.
+	FeeRecipient solana.PublicKey
+	// SystemProgram, RentSysvar and TokenMetadataProgram are the canonical ones unless set.
+	SystemProgram        solana.PublicKey
+	RentSysvar           solana.PublicKey
+	TokenMetadataProgram solana.PublicKey
+}
+
+var (
+	// MainnetAddresses are the addresses of pump.fun on mainnet, used by default.
+	MainnetAddresses = NetworkAddresses{
+		ProgramID:            solana.MustPublicKeyFromBase58("6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P"),
+		Global:               solana.MustPublicKeyFromBase58("4wTV1YmiEkRvAtNtsSGPtUrqRYQMe5SKy2uB4Jjaxnjf"),
+		MintAuthority:        solana.MustPublicKeyFromBase58("TSLvdd1pWpHVjahSpsvCXUbgwsL3JAcvokwaKt1eokM"),
+		EventAuthority:       solana.MustPublicKeyFromBase58("Ce6TQqeHC9p8KetsN6JsjHK7UTZk7nasjjnr7XxXp9F1"),
+		FeeRecipient:         solana.MustPublicKeyFromBase58("CebN5WGQ4jvEPvsVU4EoHEpgzq1VV7AbicfhtW4xC9iM"),
+		SystemProgram:        system.ProgramID,
+		RentSysvar:           solana.SysVarRentPubkey,
+		TokenMetadataProgram: solana.TokenMetadataProgramID,
+	}
+	// DevnetAddresses are the addresses of pump.fun on devnet. The program is deployed at the same address,
+	// so only the fee recipient differs, as the one of mainnet isn't initialized on devnet.
+	DevnetAddresses = NetworkAddresses{
+		ProgramID:            MainnetAddresses.ProgramID,
+		Global:               MainnetAddresses.Global,
+		MintAuthority:        MainnetAddresses.MintAuthority,
+		EventAuthority:       MainnetAddresses.EventAuthority,
+		FeeRecipient:         solana.MustPublicKeyFromBase58("68yFSZxzLWJXkxxRGydZ63C6mHx1NLEDWmwN9Lb5yySg"),
+		SystemProgram:        system.ProgramID,
+		RentSysvar:           solana.SysVarRentPubkey,
+		TokenMetadataProgram: solana.TokenMetadataProgramID,
+	}
+)
+
+// Contains commonly used addresses with the pump.fun program, that are not present
+// in the generated code, from its IDL file. They are set by SetNetworkAddresses.
+var (
+	// Global account address for pump.fun
+	globalPumpFunAddress = MainnetAddresses.Global
+	// Pump.fun mint authority
+	pumpFunMintAuthority = MainnetAddresses.MintAuthority
+	// Pump.fun event authority
+	pumpFunEventAuthority = MainnetAddresses.EventAuthority
+	// Pump.fun fee recipient
+	pumpFunFeeRecipient = MainnetAddresses.FeeRecipient
+	// Programs and sysvars used by pump.fun
+	pumpFunSystemProgram        = MainnetAddresses.SystemProgram
+	pumpFunRentSysvar           = MainnetAddresses.RentSysvar
+	pumpFunTokenMetadataProgram = MainnetAddresses.TokenMetadataProgram
+)
+
+// NewNetworkAddresses derives the addresses of a pump.fun deployment from its program ID,
+// e.g. for a copy of the program deployed on a local validator, with the canonical programs and sysvars.
+func NewNetworkAddresses(programID solana.PublicKey, feeRecipient solana.PublicKey) (NetworkAddresses, error) {
+	addresses := NetworkAddresses{
+		ProgramID:            programID,
+		FeeRecipient:         feeRecipient,
+		SystemProgram:        system.ProgramID,
+		RentSysvar:           solana.SysVarRentPubkey,
+		TokenMetadataProgram: solana.TokenMetadataProgramID,
+	}
+	for _, pda := range []struct {
+		seed    string
+		address *solana.PublicKey
+	}{
+		{"global", &addresses.Global},
+		{"mint-authority", &addresses.MintAuthority},
+		{"__event_authority", &addresses.EventAuthority},
+	} {
+		address, _, err := solana.FindProgramAddress([][]byte{[]byte(pda.seed)}, programID)
+		if err != nil {
+			return NetworkAddresses{}, fmt.Errorf("can't derive %s address: %w", pda.seed, err)
+		}
+		*pda.address = address
+	}
+	return addresses, nil
+}
+
+// SetNetworkAddresses sets the pump.fun addresses used by the SDK, MainnetAddresses by default.
+// It must be called before using the SDK, as it isn't safe for concurrent use with the other functions.
+func SetNetworkAddresses(addresses NetworkAddresses) {
+	// I know, using global variables is ugly, but passing these addresses around everywhere
+	// (in BuyToken / SellToken), while they're actually constants on mainnet is even uglier.
+	pump.SetProgramID(addresses.ProgramID)
+	globalPumpFunAddress = addresses.Global
+	pumpFunMintAuthority = addresses.MintAuthority
+	pumpFunEventAuthority = addresses.EventAuthority
+	pumpFunFeeRecipient = addresses.FeeRecipient
+	pumpFunSystemProgram = orDefault(addresses.SystemProgram, system.ProgramID)
+	pumpFunRentSysvar = orDefault(addresses.RentSysvar, solana.SysVarRentPubkey)
+	pumpFunTokenMetadataProgram = orDefault(addresses.TokenMetadataProgram, solana.TokenMetadataProgramID)
+	// The cached addresses and global account belong to the previous network.
+	bondingCurvePublicKeysCache.Clear()
+	cachedGlobal.Store(nil)
+}
+
+// orDefault returns the address, or the default one if it's zero.
+func orDefault(address solana.PublicKey, defaultAddress solana.PublicKey) solana.PublicKey {
+	if address.IsZero() {
+		return defaultAddress
+	}
+	return address
+}
+
+// SetDevnetMode sets the pump.fun program addresses to the devnet addresses.
+// It is important to call this function if you are using the devnet.
+func SetDevnetMode() {
+	SetNetworkAddresses(DevnetAddresses)
+}
@@ -0,0 +1,80 @@
+package pumpdotfunsdk
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestNewNetworkAddresses(t *testing.T) {
+	for name, want := range map[string]NetworkAddresses{"mainnet": MainnetAddresses, "devnet": DevnetAddresses} {
+		got, err := NewNetworkAddresses(want.ProgramID, want.FeeRecipient)
+		if err != nil {
+			t.Fatalf("NewNetworkAddresses() error = %s", err)
+		}
+		if got != want {
+			t.Fatalf("NewNetworkAddresses() of %s = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestSetNetworkAddresses(t *testing.T) {
+	defer SetNetworkAddresses(MainnetAddresses)
+	mint := solana.NewWallet().PublicKey()
+	mainnet, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses, err := NewNetworkAddresses(solana.NewWallet().PublicKey(), solana.NewWallet().PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetNetworkAddresses(addresses)
+	if !pump.ProgramID.Equals(addresses.ProgramID) || !globalPumpFunAddress.Equals(addresses.Global) || !pumpFunFeeRecipient.Equals(addresses.FeeRecipient) {
+		t.Fatal("SetNetworkAddresses() didn't set the addresses")
+	}
+	// The bonding curve is derived from the program ID, so it must not come from the cache.
+	local, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.BondingCurve.Equals(mainnet.BondingCurve) {
+		t.Fatal("SetNetworkAddresses() kept the bonding curve of the previous network")
+	}
+}
+
+func TestSetNetworkAddressesDefaults(t *testing.T) {
+	defer SetNetworkAddresses(MainnetAddresses)
+	mint := solana.NewWallet().PublicKey()
+	canonical, err := findTokenMetadataAddress(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _, _ := solana.FindTokenMetadataAddress(mint); !canonical.Equals(want) {
+		t.Fatalf("findTokenMetadataAddress() = %s, want %s", canonical, want)
+	}
+
+	// A fork leaving the programs and sysvars unset uses the canonical ones.
+	SetNetworkAddresses(NetworkAddresses{ProgramID: MainnetAddresses.ProgramID})
+	if !pumpFunSystemProgram.Equals(system.ProgramID) || !pumpFunRentSysvar.Equals(solana.SysVarRentPubkey) || !pumpFunTokenMetadataProgram.Equals(solana.TokenMetadataProgramID) {
+		t.Fatal("SetNetworkAddresses() didn't default the programs and sysvars")
+	}
+
+	fork := MainnetAddresses
+	fork.RentSysvar = solana.NewWallet().PublicKey()
+	fork.TokenMetadataProgram = solana.NewWallet().PublicKey()
+	SetNetworkAddresses(fork)
+	accounts, err := BuyAccounts(solana.NewWallet().PublicKey(), mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rent sysvar follows the system and token programs.
+	if !accounts[9].PublicKey.Equals(fork.RentSysvar) {
+		t.Fatalf("buy rent sysvar = %s, want %s", accounts[9].PublicKey, fork.RentSysvar)
+	}
+	if forked, _ := findTokenMetadataAddress(mint); forked.Equals(canonical) {
+		t.Fatal("findTokenMetadataAddress() ignored the token metadata program of the fork")
+	}
+}
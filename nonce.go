@@ -0,0 +1,39 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// durableNonce is a nonce account used in place of a recent blockhash.
+type durableNonce struct {
+	account   solana.PublicKey
+	authority Signer
+}
+
+// advanceInstruction returns the instruction advancing the nonce, which must be the first of the transaction.
+func (n *durableNonce) advanceInstruction() solana.Instruction {
+	return system.NewAdvanceNonceAccountInstruction(n.account, solana.SysVarRecentBlockHashesPubkey, n.authority.PublicKey()).Build()
+}
+
+// fetchNonce returns the nonce currently stored in the nonce account.
+func fetchNonce(ctx context.Context, rpcClient RPCClient, account solana.PublicKey, commitment rpc.CommitmentType) (solana.Hash, error) {
+	accountInfo, err := rpcClient.GetAccountInfoWithOpts(ctx, account, &rpc.GetAccountInfoOpts{Encoding: solana.EncodingBase64, Commitment: commitment})
+	if err != nil {
+		return solana.Hash{}, fmt.Errorf("can't get nonce account: %w", err)
+	}
+	var nonceAccount system.NonceAccount
+	if err := bin.NewBinDecoder(accountInfo.Value.Data.GetBinary()).Decode(&nonceAccount); err != nil {
+		return solana.Hash{}, fmt.Errorf("can't decode nonce account: %w", err)
+	}
+	// The state of an initialized nonce account is 1.
+	if nonceAccount.State != 1 {
+		return solana.Hash{}, fmt.Errorf("nonce account %s is not initialized", account)
+	}
+	return solana.Hash(nonceAccount.Nonce), nil
+}
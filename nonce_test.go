@@ -0,0 +1,40 @@
+package pumpdotfunsdk
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+func TestBuildTransactionWithDurableNonce(t *testing.T) {
+	user := solana.NewWallet().PrivateKey
+	authority := solana.NewWallet().PrivateKey
+	nonceAccount := solana.NewWallet().PublicKey()
+	nonce := solana.NewWallet().PublicKey()
+	var buf bytes.Buffer
+	err := bin.NewBinEncoder(&buf).Encode(system.NonceAccount{Version: 1, State: 1, AuthorizedPubkey: authority.PublicKey(), Nonce: nonce})
+	if err != nil {
+		t.Fatalf("can't encode nonce account: %s", err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{nonceAccount: buf.Bytes()}}
+	o := newOptions([]Option{WithDurableNonce(nonceAccount, authority)})
+	transfer := system.NewTransferInstruction(1, user.PublicKey(), user.PublicKey()).Build()
+	tx, err := buildTransaction(context.Background(), rpcClient, []solana.Instruction{transfer}, o, user)
+	if err != nil {
+		t.Fatalf("buildTransaction() error = %s", err)
+	}
+	if tx.Message.RecentBlockhash != solana.Hash(nonce) {
+		t.Fatalf("buildTransaction() blockhash = %s, want the nonce %s", tx.Message.RecentBlockhash, nonce)
+	}
+	first := tx.Message.Instructions[0]
+	if program := tx.Message.AccountKeys[first.ProgramIDIndex]; !program.Equals(system.ProgramID) || uint32(first.Data[0]) != system.Instruction_AdvanceNonceAccount {
+		t.Fatalf("buildTransaction() first instruction = %v of %s, want AdvanceNonceAccount", first.Data, program)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Fatalf("buildTransaction() signatures: %s", err)
+	}
+}
@@ -0,0 +1,417 @@
+package pumpdotfunsdk
+
+import (
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Option allows to customize the behaviour of CreateToken, BuyToken and SellToken.
+// Options that don't apply to a function are ignored by it.
+type Option func(*options)
+
+type options struct {
+	dryRun bool
+	// Commitment used to fetch the recent blockhash of the transactions.
+	blockhashCommitment rpc.CommitmentType
+	// Initial buy of CreateToken, expressed in tokens or in percentage of the total supply.
+	initialBuyTokens     TokenAmount
+	initialBuyPercentage float64
+	// Commitment used to fetch the state the quote is computed from, blockhashCommitment if empty.
+	quoteCommitment rpc.CommitmentType
+	// Hard cap on the SOL spent by a buy, no cap if 0.
+	maxSolCost Lamports
+	// Commitment and timeout of the confirmation of the transactions.
+	confirmCommitment rpc.CommitmentType
+	confirmTimeout    time.Duration
+	// Provides the compute unit price of the transactions, overriding the default ones.
+	priorityFeeProvider PriorityFeeProvider
+	// Called with the built transaction, before it gets signed.
+	preSignHook func(tx *solana.Transaction) error
+	// Pays the fees of the transactions instead of the user.
+	feePayer Signer
+	// Appended to the transactions, after the pump.fun instructions.
+	extraInstructions []solana.Instruction
+	// Memo added to the transactions, none if empty.
+	memo string
+	// Checks the balance of the wallet before building the transactions.
+	balanceCheck bool
+	// Fee charged by pump.fun on trades, fetched from the global account if nil.
+	feeBasisPoints *uint64
+	// Also send the transactions with these clients, concurrently.
+	broadcastClients []RPCClient
+	// Bonding curve the quote is computed from, fetched if nil.
+	bondingCurve *BondingCurveData
+	// Omits the compute budget instructions from the transactions.
+	withoutComputeBudget bool
+	// Token balance sold by SellToken with all, fetched if nil.
+	tokenBalance *TokenAmount
+	// Compute unit price used by CreateToken when there are no recent prioritization fees.
+	defaultComputeUnitPrice uint64
+	// Called at each stage of the functions.
+	onProgress func(stage string)
+	// Resends the transactions with a higher compute unit price when they aren't confirmed in time.
+	feeEscalation *feeEscalation
+	// Token program of the mints, the SPL token program if zero.
+	tokenProgram solana.PublicKey
+	// Nonce account used in place of a recent blockhash.
+	durableNonce *durableNonce
+	// Logs the key steps of the functions.
+	logger Logger
+	// Creates the associated token account in its own confirmed transaction before the buy.
+	separateAtaTransaction bool
+	// Caps the fees of a buy to this fraction of its amount.
+	maxFeeFraction float64
+	// Minimum SOL output of the sells, replacing the one computed from the slippage.
+	minSolOutput *Lamports
+	// Minimum tokens the buys must receive, replacing the one computed from the slippage.
+	minTokens *TokenAmount
+	// Number of times the HTTP requests are retried after a 5xx or 429 response.
+	httpRetries int
+	// Blockhash of the transactions, instead of fetching a recent one.
+	blockhash *solana.Hash
+	// Provides the blockhash of the transactions, instead of the RPC.
+	blockhashProvider BlockhashProvider
+	// Scales the compute unit price of the transactions, unscaled if 0.
+	priorityFeeMultiplier float64
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		blockhashCommitment:     rpc.CommitmentConfirmed,
+		confirmCommitment:       rpc.CommitmentFinalized,
+		confirmTimeout:          2 * time.Minute,
+		defaultComputeUnitPrice: defaultCreateComputeUnitPrice,
+		logger:                  noopLogger{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// getQuoteCommitment returns the commitment used to fetch the state the quote is computed from.
+func (o *options) getQuoteCommitment() rpc.CommitmentType {
+	if o.quoteCommitment == "" {
+		return o.blockhashCommitment
+	}
+	return o.quoteCommitment
+}
+
+// getTokenProgram returns the token program of the mints.
+func (o *options) getTokenProgram() solana.PublicKey {
+	if o.tokenProgram.IsZero() {
+		return token.ProgramID
+	}
+	return o.tokenProgram
+}
+
+// Stages reported to the WithOnProgress hook.
+const (
+	StageBuildingTransaction  = "building transaction"
+	StageSendingTransaction   = "sending transaction"
+	StageAwaitingConfirmation = "awaiting confirmation"
+)
+
+// progress reports the stage to the WithOnProgress hook, if any.
+func (o *options) progress(stage string) {
+	if o.onProgress != nil {
+		o.onProgress(stage)
+	}
+}
+
+// trailingInstructions returns the instructions to add after the pump.fun instructions.
+func (o *options) trailingInstructions() []solana.Instruction {
+	instructions := append([]solana.Instruction{}, o.extraInstructions...)
+	if o.memo != "" {
+		instructions = append(instructions, solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte(o.memo)))
+	}
+	return instructions
+}
+
+// WithDryRun builds and signs the transaction, but returns it instead of sending it,
+// so it can be inspected or simulated first.
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// WithInitialBuyTokens makes CreateToken buy the given amount of tokens in the create transaction,
+// instead of spending buyAmountLamports. The buy is for exactly that amount, and the slippage
+// applies to the most SOL it can spend over its cost on the initial bonding curve.
+func WithInitialBuyTokens(tokenAmount TokenAmount) Option {
+	return func(o *options) {
+		o.initialBuyTokens = tokenAmount
+	}
+}
+
+// WithInitialBuyPercentage makes CreateToken buy the given percentage of the total supply in the
+// create transaction, instead of spending buyAmountLamports. Use 5 for 5%. Like WithInitialBuyTokens,
+// the slippage applies to the SOL spent.
+func WithInitialBuyPercentage(percentage float64) Option {
+	return func(o *options) {
+		o.initialBuyPercentage = percentage
+	}
+}
+
+// WithBlockhashCommitment sets the commitment used to fetch the recent blockhash of the transaction,
+// rpc.CommitmentConfirmed by default. A finalized blockhash is older, and thus expires sooner.
+func WithBlockhashCommitment(commitment rpc.CommitmentType) Option {
+	return func(o *options) {
+		o.blockhashCommitment = commitment
+	}
+}
+
+// WithQuoteCommitment sets the commitment used to fetch the bonding curve and token balance
+// the quote is computed from. It defaults to the commitment used for the blockhash,
+// so the quote and the transaction see the same state of the chain.
+// rpc.CommitmentProcessed gives fresher reserves, at the risk of them being rolled back.
+func WithQuoteCommitment(commitment rpc.CommitmentType) Option {
+	return func(o *options) {
+		o.quoteCommitment = commitment
+	}
+}
+
+// WithMaxSolCost caps the SOL a buy can spend, whatever the requested amount,
+// as a guardrail against fat-finger amounts. The tokens are quoted for the capped amount.
+func WithMaxSolCost(lamports Lamports) Option {
+	return func(o *options) {
+		o.maxSolCost = lamports
+	}
+}
+
+// WithConfirmCommitment sets the commitment to wait for when confirming a transaction,
+// rpc.CommitmentFinalized by default. Use rpc.CommitmentConfirmed to return sooner.
+func WithConfirmCommitment(commitment rpc.CommitmentType) Option {
+	return func(o *options) {
+		o.confirmCommitment = commitment
+	}
+}
+
+// WithConfirmTimeout sets how long to wait for the confirmation of a transaction, 2 minutes by default.
+// A *ConfirmationTimeoutError is returned when it is exceeded.
+func WithConfirmTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.confirmTimeout = timeout
+	}
+}
+
+// WithPriorityFeeProvider sets the function called to get the compute unit price of each transaction,
+// overriding the default prices and the estimation from the recent prioritization fees.
+func WithPriorityFeeProvider(provider PriorityFeeProvider) Option {
+	return func(o *options) {
+		o.priorityFeeProvider = provider
+	}
+}
+
+// WithPreSignHook sets a hook called with the built transaction, before it gets signed by the signers passed to the function.
+// It allows external signers, e.g. a hardware wallet or a KMS, to sign the message of the transaction
+// and set their signatures in tx.Signatures, at the index of their key in tx.Message.AccountKeys.
+func WithPreSignHook(hook func(tx *solana.Transaction) error) Option {
+	return func(o *options) {
+		o.preSignHook = hook
+	}
+}
+
+// WithFeePayer makes the payer pay the base and priority fees of the transaction instead of the user,
+// e.g. a relayer paying for the trades of its users. The trade itself is still made by, and for, the user.
+func WithFeePayer(payer Signer) Option {
+	return func(o *options) {
+		o.feePayer = payer
+	}
+}
+
+// WithExtraInstructions appends the instructions to the transaction, after the pump.fun instructions,
+// e.g. to add a memo, a tip, or a transfer to the same transaction.
+func WithExtraInstructions(instructions ...solana.Instruction) Option {
+	return func(o *options) {
+		o.extraInstructions = append(o.extraInstructions, instructions...)
+	}
+}
+
+// WithMemo adds a memo program instruction with the memo to the transaction, e.g. to tag trades with a bot ID.
+func WithMemo(memo string) Option {
+	return func(o *options) {
+		o.memo = memo
+	}
+}
+
+// WithBalanceCheck makes BuyToken and SellToken check that the wallet can pay for the transaction before
+// building it, returning an *InsufficientFundsError with the shortfall otherwise, instead of wasting the
+// base fee on a transaction reverting on-chain. It costs an extra RPC call or two.
+func WithBalanceCheck() Option {
+	return func(o *options) {
+		o.balanceCheck = true
+	}
+}
+
+// WithFeeBasisPoints sets the fee charged by pump.fun on trades, used to compute the quotes,
+// instead of fetching it from the global account.
+func WithFeeBasisPoints(feeBasisPoints uint64) Option {
+	return func(o *options) {
+		o.feeBasisPoints = &feeBasisPoints
+	}
+}
+
+// WithBroadcast also sends the transaction with the clients, concurrently with the one passed to the function,
+// returning as soon as one of them accepts it. See BroadcastTransaction.
+func WithBroadcast(clients ...RPCClient) Option {
+	return func(o *options) {
+		o.broadcastClients = append(o.broadcastClients, clients...)
+	}
+}
+
+// WithBondingCurve makes BuyToken and SellToken compute the quote from the bonding curve,
+// e.g. decoded from a websocket feed, instead of fetching it. It saves an RPC call,
+// but the quote is only as fresh as the bonding curve.
+func WithBondingCurve(bondingCurve *BondingCurveData) Option {
+	return func(o *options) {
+		o.bondingCurve = bondingCurve
+	}
+}
+
+// WithoutComputeBudget omits the compute unit limit and price instructions from the transactions of BuyToken and SellToken,
+// e.g. when WithExtraInstructions already sets them, as a transaction can't hold two of the same compute budget instruction.
+func WithoutComputeBudget() Option {
+	return func(o *options) {
+		o.withoutComputeBudget = true
+	}
+}
+
+// WithTokenBalance sets the token balance of the user, e.g. known from a previous buy,
+// so that SellToken with all sells it without fetching it first.
+func WithTokenBalance(balance TokenAmount) Option {
+	return func(o *options) {
+		o.tokenBalance = &balance
+	}
+}
+
+// WithDefaultComputeUnitPrice sets the compute unit price, in micro-lamports, used by CreateToken
+// when the RPC returns no recent prioritization fees to estimate it from.
+func WithDefaultComputeUnitPrice(computeUnitPrice uint64) Option {
+	return func(o *options) {
+		o.defaultComputeUnitPrice = computeUnitPrice
+	}
+}
+
+// WithOnProgress sets a hook called at each stage of CreateToken, BuyToken and SellToken,
+// with StageBuildingTransaction, StageSendingTransaction, then StageAwaitingConfirmation if the function waits for it.
+func WithOnProgress(onProgress func(stage string)) Option {
+	return func(o *options) {
+		o.onProgress = onProgress
+	}
+}
+
+// WithFeeEscalation makes BuyToken and SellToken wait for the confirmation of the transaction and, if it isn't
+// confirmed within the confirm timeout, rebuild it with a fresh blockhash and the compute unit price times the multiplier,
+// capped to maxComputeUnitPrice if not 0, and resend it, up to attempts times in total.
+// A replaced transaction may still land until its blockhash expires, so the previous attempts are checked before
+// each resend, but two attempts can still both land in rare cases.
+func WithFeeEscalation(attempts int, multiplier float64, maxComputeUnitPrice uint64) Option {
+	return func(o *options) {
+		o.feeEscalation = &feeEscalation{
+			attempts:            attempts,
+			multiplier:          multiplier,
+			maxComputeUnitPrice: maxComputeUnitPrice,
+		}
+	}
+}
+
+// WithTokenProgram sets the token program of the mint created by CreateToken, traded by BuyToken, SellToken and ClosePosition,
+// or looked up by GetTokenHolders and GetAssociatedBondingCurveBalance, e.g. solana.Token2022ProgramID,
+// used to derive the associated token accounts. The SPL token program by default.
+func WithTokenProgram(tokenProgram solana.PublicKey) Option {
+	return func(o *options) {
+		o.tokenProgram = tokenProgram
+	}
+}
+
+// WithDurableNonce builds the transactions against the nonce stored in the nonce account, advanced by the authority,
+// instead of a recent blockhash, so they don't expire until the nonce is advanced. It allows to sign them in advance,
+// and to resend them safely, as only one transaction using the same nonce can land.
+func WithDurableNonce(nonceAccount solana.PublicKey, authority Signer) Option {
+	return func(o *options) {
+		o.durableNonce = &durableNonce{account: nonceAccount, authority: authority}
+	}
+}
+
+// WithLogger logs the key steps of the functions with the logger: derived addresses, fetched reserves,
+// computed quotes, chosen priority fees and sent transactions. Nothing is logged by default.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithSeparateAtaTransaction makes BuyToken create the associated token account of the user, if needed,
+// in its own transaction, and wait for its confirmation before sending the buy.
+// The buy transaction is smaller, and a failed buy doesn't prevent the account creation, at the cost of latency.
+func WithSeparateAtaTransaction() Option {
+	return func(o *options) {
+		o.separateAtaTransaction = true
+	}
+}
+
+// WithMaxFeeFraction caps the compute unit price of BuyToken, including the fee escalation,
+// so that the priority fee and the base fee don't exceed this fraction of the buy amount, e.g. 0.1 for 10%.
+func WithMaxFeeFraction(fraction float64) Option {
+	return func(o *options) {
+		o.maxFeeFraction = fraction
+	}
+}
+
+// WithMinSolOutput sets the minimum SOL the sells must receive, like a limit order,
+// replacing the one computed from the slippage, which is then ignored. The sells fail with ErrTooLittleSolReceived,
+// without being sent, if it is over what the bonding curve would pay. With WithBondingCurve, which may be stale,
+// they are sent anyway, and the program checks it.
+func WithMinSolOutput(lamports Lamports) Option {
+	return func(o *options) {
+		o.minSolOutput = &lamports
+	}
+}
+
+// WithMinTokens sets the minimum tokens the buys must receive, replacing the one computed from the slippage,
+// which is then ignored. It must be positive.
+func WithMinTokens(tokenAmount TokenAmount) Option {
+	return func(o *options) {
+		o.minTokens = &tokenAmount
+	}
+}
+
+// WithHTTPRetries makes CreateTokenMetadata retry its requests up to retries times, with backoff,
+// when pump.fun answers with a server error or a rate limit.
+func WithHTTPRetries(retries int) Option {
+	return func(o *options) {
+		o.httpRetries = retries
+	}
+}
+
+// WithBlockhash builds the transactions against the blockhash instead of fetching a recent one,
+// e.g. to pre-sign them, or to simulate them against a known slot. The transactions are then not rebuilt
+// when the blockhash is not found. A durable nonce takes precedence over it.
+func WithBlockhash(blockhash solana.Hash) Option {
+	return func(o *options) {
+		o.blockhash = &blockhash
+	}
+}
+
+// WithBlockhashProvider gets the blockhash of the transactions from the provider instead of the RPC.
+// The provider is called again to rebuild a transaction whose blockhash was not found,
+// which is only sent again if the provider returns another blockhash.
+func WithBlockhashProvider(provider BlockhashProvider) Option {
+	return func(o *options) {
+		o.blockhashProvider = provider
+	}
+}
+
+// WithPriorityFeeMultiplier scales the compute unit price of the transactions by the multiplier, e.g. 1.5 for 50% more,
+// whether it comes from the recent prioritization fees, a PriorityFeeProvider or the defaults,
+// to land more reliably without tuning absolute prices. The price is still capped by WithMaxFeeFraction.
+func WithPriorityFeeMultiplier(multiplier float64) Option {
+	return func(o *options) {
+		o.priorityFeeMultiplier = multiplier
+	}
+}
@@ -0,0 +1,111 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var (
+	// ErrNoTradeEvent is returned when a transaction holds no pump.fun trade.
+	ErrNoTradeEvent = errors.New("no trade event in transaction")
+	// ErrNoCreateEvent is returned when a transaction doesn't create a pump.fun token.
+	ErrNoCreateEvent = errors.New("no create event in transaction")
+)
+
+// GetTransactionTrade returns the pump.fun trade made by a confirmed transaction, e.g. to get the actual fill of BuyToken.
+// If the transaction made several trades, the first one is returned.
+func GetTransactionTrade(ctx context.Context, rpcClient RPCClient, sig solana.Signature) (*TradeEvent, error) {
+	out, err := getTransaction(ctx, rpcClient, sig)
+	if err != nil {
+		return nil, err
+	}
+	events := parseTradeEvents(out.Meta.LogMessages)
+	if len(events) == 0 {
+		return nil, ErrNoTradeEvent
+	}
+	return &events[0], nil
+}
+
+// ConfirmAndParse waits for the confirmation of the trade transaction, e.g. sent by BuyToken, until the confirm timeout
+// of the options, and returns its Fill parsed from the transaction. Only the Signature, the Slot and the Fill of the result are set.
+// It returns the error of the program if the transaction failed.
+func ConfirmAndParse(ctx context.Context, rpcClient RPCClient, sig solana.Signature, opts ...Option) (*TradeResult, error) {
+	o := newOptions(opts)
+	confirmation, err := confirmTransaction(ctx, rpcClient, nil, sig, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't confirm transaction %s: %w", sig, err)
+	}
+	if confirmation.Err != nil {
+		return nil, fmt.Errorf("transaction %s failed: %w", sig, revertError(ctx, rpcClient, sig, confirmation.Err))
+	}
+	fill, err := GetTransactionTrade(ctx, rpcClient, sig)
+	if err != nil {
+		return nil, err
+	}
+	return &TradeResult{Signature: sig, Slot: confirmation.Slot, Fill: fill}, nil
+}
+
+// GetTransactionMint returns the mint of the token created by a confirmed transaction,
+// e.g. to recover it when only the signature of CreateToken is known.
+func GetTransactionMint(ctx context.Context, rpcClient RPCClient, sig solana.Signature) (solana.PublicKey, error) {
+	out, err := getTransaction(ctx, rpcClient, sig)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	events := parseCreateEvents(out.Meta.LogMessages)
+	if len(events) == 0 {
+		return solana.PublicKey{}, ErrNoCreateEvent
+	}
+	return events[0].Mint, nil
+}
+
+// getTransaction fetches a confirmed transaction, and returns an error if it failed.
+func getTransaction(ctx context.Context, rpcClient RPCClient, sig solana.Signature) (*rpc.GetTransactionResult, error) {
+	out, err := fetchTransaction(ctx, rpcClient, sig)
+	if err != nil {
+		return nil, fmt.Errorf("can't get transaction %s: %w", sig, err)
+	}
+	if out.Meta == nil {
+		return nil, fmt.Errorf("transaction %s has no metadata", sig)
+	}
+	if out.Meta.Err != nil {
+		return nil, fmt.Errorf("transaction %s failed: %w", sig, MapTransactionError(&transactionError{err: out.Meta.Err}, decodeTransaction(out)))
+	}
+	return out, nil
+}
+
+// fetchTransaction fetches a confirmed transaction, encoded in base64.
+func fetchTransaction(ctx context.Context, rpcClient RPCClient, sig solana.Signature) (*rpc.GetTransactionResult, error) {
+	maxSupportedTransactionVersion := uint64(0)
+	return rpcClient.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		Commitment:                     rpc.CommitmentConfirmed,
+		MaxSupportedTransactionVersion: &maxSupportedTransactionVersion,
+	})
+}
+
+// decodeTransaction returns the transaction of the result, nil if it can't be decoded.
+func decodeTransaction(out *rpc.GetTransactionResult) *solana.Transaction {
+	if out.Transaction == nil {
+		return nil
+	}
+	tx, err := out.Transaction.GetTransaction()
+	if err != nil {
+		return nil
+	}
+	return tx
+}
+
+// revertError returns the error of the reverted transaction, mapped with the programs of the transaction
+// fetched from the RPC, as its status doesn't tell them. It is left unmapped if the transaction can't be fetched.
+func revertError(ctx context.Context, rpcClient RPCClient, sig solana.Signature, revert error) error {
+	out, err := fetchTransaction(ctx, rpcClient, sig)
+	if err != nil {
+		return MapProgramError(revert)
+	}
+	return MapTransactionError(revert, decodeTransaction(out))
+}
@@ -0,0 +1,144 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// transactionEnvelope returns the transaction as returned by getTransaction, encoded in base64.
+func transactionEnvelope(t *testing.T, tx *solana.Transaction) *rpc.TransactionResultEnvelope {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("can't encode transaction: %s", err)
+	}
+	var envelope rpc.TransactionResultEnvelope
+	if err := envelope.UnmarshalJSON([]byte(fmt.Sprintf(`[%q,"base64"]`, base64.StdEncoding.EncodeToString(data)))); err != nil {
+		t.Fatalf("can't decode transaction envelope: %s", err)
+	}
+	return &envelope
+}
+
+func TestGetTransactionTrade(t *testing.T) {
+	want := TradeEvent{
+		Mint:        solana.NewWallet().PublicKey(),
+		SolAmount:   100000000,
+		TokenAmount: 3500000000000,
+		IsBuy:       true,
+		User:        solana.NewWallet().PublicKey(),
+	}
+	trade, noTrade, failed := solana.Signature{1}, solana.Signature{2}, solana.Signature{3}
+	rpcClient := &mockRPCClient{transactions: map[solana.Signature]*rpc.GetTransactionResult{
+		trade:   {Meta: &rpc.TransactionMeta{LogMessages: []string{eventLog(t, tradeEventDiscriminator, want)}}},
+		noTrade: {Meta: &rpc.TransactionMeta{}},
+		failed: {
+			Transaction: transactionEnvelope(t, sellTransaction(t, want.User)),
+			Meta:        &rpc.TransactionMeta{Err: map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": 6003}}}},
+		},
+	}}
+	tests := []struct {
+		name    string
+		sig     solana.Signature
+		wantErr error
+	}{
+		{"trade", trade, nil},
+		{"no trade", noTrade, ErrNoTradeEvent},
+		{"failed", failed, ErrSlippageExceeded},
+		{"not found", solana.Signature{4}, rpc.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetTransactionTrade(context.Background(), rpcClient, tt.sig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetTransactionTrade() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && *got != want {
+				t.Fatalf("GetTransactionTrade() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestGetTransactionMint(t *testing.T) {
+	want := CreateEvent{
+		Name:   "Test",
+		Symbol: "TEST",
+		Uri:    "https://ipfs.io/ipfs/test",
+		Mint:   solana.NewWallet().PublicKey(),
+		User:   solana.NewWallet().PublicKey(),
+	}
+	create, trade := solana.Signature{1}, solana.Signature{2}
+	rpcClient := &mockRPCClient{transactions: map[solana.Signature]*rpc.GetTransactionResult{
+		create: {Meta: &rpc.TransactionMeta{LogMessages: []string{eventLog(t, createEventDiscriminator, want)}}},
+		trade:  {Meta: &rpc.TransactionMeta{LogMessages: []string{eventLog(t, tradeEventDiscriminator, TradeEvent{})}}},
+	}}
+	got, err := GetTransactionMint(context.Background(), rpcClient, create)
+	if err != nil {
+		t.Fatalf("GetTransactionMint() error = %s", err)
+	}
+	if got != want.Mint {
+		t.Fatalf("GetTransactionMint() = %s, want %s", got, want.Mint)
+	}
+	if _, err := GetTransactionMint(context.Background(), rpcClient, trade); !errors.Is(err, ErrNoCreateEvent) {
+		t.Fatalf("GetTransactionMint() of a trade error = %v, want %v", err, ErrNoCreateEvent)
+	}
+}
+
+func TestConfirmAndParse(t *testing.T) {
+	want := TradeEvent{
+		Mint:        solana.NewWallet().PublicKey(),
+		SolAmount:   100000000,
+		TokenAmount: 3500000000000,
+		IsBuy:       true,
+		User:        solana.NewWallet().PublicKey(),
+	}
+	failedErr := map[string]interface{}{"InstructionError": []interface{}{2, map[string]interface{}{"Custom": 6003}}}
+	landed, failed, pending := solana.Signature{1}, solana.Signature{2}, solana.Signature{3}
+	rpcClient := &mockRPCClient{
+		transactions: map[solana.Signature]*rpc.GetTransactionResult{
+			landed: {Meta: &rpc.TransactionMeta{LogMessages: []string{eventLog(t, tradeEventDiscriminator, want)}}},
+			failed: {Transaction: transactionEnvelope(t, sellTransaction(t, want.User)), Meta: &rpc.TransactionMeta{Err: failedErr}},
+		},
+		statuses: map[solana.Signature]*rpc.SignatureStatusesResult{
+			landed: {ConfirmationStatus: rpc.ConfirmationStatusFinalized},
+			failed: {ConfirmationStatus: rpc.ConfirmationStatusFinalized, Err: failedErr},
+		},
+	}
+	tests := []struct {
+		name    string
+		sig     solana.Signature
+		wantErr error
+	}{
+		{"landed", landed, nil},
+		{"failed", failed, ErrSlippageExceeded},
+		{"not confirmed in time", pending, &ConfirmationTimeoutError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConfirmAndParse(context.Background(), rpcClient, tt.sig, WithConfirmTimeout(10*time.Millisecond))
+			switch wantErr := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("ConfirmAndParse() error = %s", err)
+				}
+				if got.Signature != tt.sig || *got.Fill != want {
+					t.Fatalf("ConfirmAndParse() = %+v, want the fill %+v", got, want)
+				}
+			case *ConfirmationTimeoutError:
+				if !errors.As(err, &wantErr) {
+					t.Fatalf("ConfirmAndParse() error = %v, want a %T", err, wantErr)
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ConfirmAndParse() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// ErrNoPosition is returned by ClosePosition when the user has no associated token account for the mint.
+var ErrNoPosition = errors.New("no associated token account to close")
+
+// ClosePosition sells all the tokens of the user, and closes its associated token account to reclaim its rent,
+// in a single transaction. If the balance is already 0, the account is only closed.
+func ClosePosition(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsClient *ws.Client,
+	user Signer,
+	mint solana.PublicKey,
+	slippageBasisPoint uint,
+	opts ...Option,
+) (*TradeResult, error) {
+	o := newOptions(opts)
+	ata, err := findAssociatedTokenAddress(user.PublicKey(), mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	if o.tokenBalance == nil {
+		noAta, err := shouldCreateAta(ctx, rpcClient, ata)
+		if err != nil {
+			return nil, fmt.Errorf("can't check if the ATA exists: %w", err)
+		}
+		if noAta {
+			return nil, ErrNoPosition
+		}
+	}
+	balance, err := getTokenBalance(ctx, rpcClient, ata, o)
+	if err != nil {
+		return nil, err
+	}
+	computeUnitPrice, err := getComputeUnitPrice(ctx, o, func() (uint64, error) {
+		return defaultSellComputeUnitPrice, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute unit price: %w", err)
+	}
+	var positionInstructions []solana.Instruction
+	if balance > 0 {
+		sell, err := getSellInstructions(ctx, rpcClient, user.PublicKey(), mint, balance, slippageBasisPoint, false, o)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sell instructions: %w", err)
+		}
+		positionInstructions = append(positionInstructions, sell)
+	}
+	positionInstructions = append(positionInstructions, newCloseAccountInstruction(ata, user.PublicKey(), user.PublicKey(), o.getTokenProgram()))
+	build := tradeTransactionBuilder(ctx, rpcClient, user, positionInstructions, o)
+	return sendTrade(ctx, rpcClient, wsClient, computeUnitPrice, 0, build, o)
+}
+
+// maxClosesPerTransaction is the most close account instructions CloseEmptyAtas puts in a transaction,
+// keeping it under the size limit of a transaction.
+const maxClosesPerTransaction = 20
+
+// CloseAtasResult is the result of CloseEmptyAtas.
+type CloseAtasResult struct {
+	// Signatures are the signatures of the transactions closing the accounts.
+	Signatures []solana.Signature
+	// Closed are the mints whose associated token account was closed.
+	Closed []solana.PublicKey
+	// Skipped are the mints whose associated token account wasn't closed, with the reason why.
+	Skipped map[solana.PublicKey]string
+	// ReclaimedRent is the rent of the closed accounts, sent back to the user.
+	ReclaimedRent Lamports
+}
+
+// CloseEmptyAtas closes the empty associated token accounts of the user for the mints, to reclaim their rent,
+// with as few transactions as possible, each sent and confirmed in turn. The accounts holding tokens,
+// and the missing ones, are skipped. On error, the result holds the accounts closed so far.
+func CloseEmptyAtas(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsClient *ws.Client,
+	user Signer,
+	mints []solana.PublicKey,
+	opts ...Option,
+) (*CloseAtasResult, error) {
+	o := newOptions(opts)
+	result := &CloseAtasResult{Skipped: make(map[solana.PublicKey]string)}
+	atas := make([]solana.PublicKey, len(mints))
+	for i, mint := range mints {
+		ata, err := findAssociatedTokenAddress(user.PublicKey(), mint, o.getTokenProgram())
+		if err != nil {
+			return nil, err
+		}
+		atas[i] = ata
+	}
+	var closes []solana.Instruction
+	var closed []solana.PublicKey
+	var rents []Lamports
+	for start := 0; start < len(atas); start += maxMultipleAccounts {
+		end := min(start+maxMultipleAccounts, len(atas))
+		accounts, err := rpcClient.GetMultipleAccountsWithOpts(ctx, atas[start:end], &rpc.GetMultipleAccountsOpts{Encoding: solana.EncodingBase64, Commitment: rpc.CommitmentConfirmed})
+		if err != nil {
+			return nil, fmt.Errorf("can't get associated token accounts: %w", err)
+		}
+		for i, account := range accounts.Value {
+			mint := mints[start+i]
+			if account == nil {
+				result.Skipped[mint] = "no associated token account"
+				continue
+			}
+			var tokenAccount token.Account
+			if err := bin.NewBinDecoder(account.Data.GetBinary()).Decode(&tokenAccount); err != nil {
+				return nil, fmt.Errorf("can't decode associated token account of %s: %w", mint, err)
+			}
+			if tokenAccount.Amount > 0 {
+				result.Skipped[mint] = fmt.Sprintf("holds %d tokens", tokenAccount.Amount)
+				continue
+			}
+			closes = append(closes, newCloseAccountInstruction(atas[start+i], user.PublicKey(), user.PublicKey(), o.getTokenProgram()))
+			closed = append(closed, mint)
+			rents = append(rents, Lamports(account.Lamports))
+		}
+	}
+	if len(closes) == 0 {
+		return result, nil
+	}
+	computeUnitPrice, err := getComputeUnitPrice(ctx, o, func() (uint64, error) {
+		return defaultSellComputeUnitPrice, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute unit price: %w", err)
+	}
+	for start := 0; start < len(closes); start += maxClosesPerTransaction {
+		end := min(start+maxClosesPerTransaction, len(closes))
+		instructions := append(computeBudgetInstructions(computeUnitPrice, o), closes[start:end]...)
+		tx, err := buildTransaction(ctx, rpcClient, instructions, o, user)
+		if err != nil {
+			return result, err
+		}
+		confirmation, err := sendAndConfirmTransaction(ctx, rpcClient, wsClient, tx, o)
+		if err != nil {
+			return result, fmt.Errorf("can't close associated token accounts: %w", MapProgramError(err))
+		}
+		o.logger.Info("closed associated token accounts", "signature", confirmation.Signature, "count", end-start)
+		result.Signatures = append(result.Signatures, confirmation.Signature)
+		result.Closed = append(result.Closed, closed[start:end]...)
+		for _, rent := range rents[start:end] {
+			result.ReclaimedRent += rent
+		}
+	}
+	return result, nil
+}
+
+// Position is the tokens of a mint held after a series of trades, and what they cost.
+type Position struct {
+	Tokens TokenAmount
+	// CostBasis is the SOL spent on the tokens held, as reported by the fills.
+	CostBasis Lamports
+	// AveragePrice is the weighted-average entry price, in SOL per token, 0 without tokens.
+	AveragePrice float64
+}
+
+// AveragePosition returns the position built by the fills of the mint, e.g. the Fill of the TradeResult of each trade,
+// in the order they were made. The fills of other mints are skipped. The sells reduce the cost basis at the average cost,
+// so they don't change the average price.
+func AveragePosition(mint solana.PublicKey, fills []TradeEvent) Position {
+	var tokens, cost uint64
+	for _, fill := range fills {
+		if !fill.Mint.Equals(mint) {
+			continue
+		}
+		if fill.IsBuy {
+			tokens += uint64(fill.TokenAmount)
+			cost += uint64(fill.SolAmount)
+			continue
+		}
+		sold := min(uint64(fill.TokenAmount), tokens)
+		if sold == tokens {
+			tokens, cost = 0, 0
+			continue
+		}
+		// cost -= cost * sold / tokens, without overflowing.
+		soldCost := new(big.Int).Mul(new(big.Int).SetUint64(cost), new(big.Int).SetUint64(sold))
+		soldCost.Div(soldCost, new(big.Int).SetUint64(tokens))
+		cost -= soldCost.Uint64()
+		tokens -= sold
+	}
+	position := Position{Tokens: TokenAmount(tokens), CostBasis: Lamports(cost)}
+	if tokens > 0 {
+		position.AveragePrice = LamportsToSol(position.CostBasis) / (float64(tokens) / tokenUnit)
+	}
+	return position
+}
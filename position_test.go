@@ -0,0 +1,94 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestCloseEmptyAtas(t *testing.T) {
+	const rent = 2039280
+	user := solana.NewWallet().PrivateKey
+	rpcClient := &mockRPCClient{
+		accounts:     map[solana.PublicKey][]byte{},
+		lamports:     map[solana.PublicKey]uint64{},
+		statuses:     map[solana.Signature]*rpc.SignatureStatusesResult{},
+		finalizeSent: true,
+	}
+	// More empty accounts than fit in a transaction, one holding tokens, and one missing.
+	mints := make([]solana.PublicKey, maxClosesPerTransaction+7)
+	for i := range mints {
+		mints[i] = solana.NewWallet().PublicKey()
+		if i == len(mints)-1 {
+			continue
+		}
+		ata, err := findAssociatedTokenAddress(user.PublicKey(), mints[i], solana.TokenProgramID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var amount uint64
+		if i == len(mints)-2 {
+			amount = 1000000
+		}
+		rpcClient.accounts[ata] = encodeAccount(t, token.Account{Mint: mints[i], Owner: user.PublicKey(), Amount: amount})
+		rpcClient.lamports[ata] = rent
+	}
+	got, err := CloseEmptyAtas(context.Background(), rpcClient, nil, user, mints)
+	if err != nil {
+		t.Fatalf("CloseEmptyAtas() error = %s", err)
+	}
+	wantClosed := len(mints) - 2
+	if len(got.Closed) != wantClosed || len(got.Skipped) != 2 || got.ReclaimedRent != Lamports(wantClosed*rent) {
+		t.Fatalf("CloseEmptyAtas() closed %d, skipped %d, reclaimed %d, want %d, 2, %d", len(got.Closed), len(got.Skipped), got.ReclaimedRent, wantClosed, wantClosed*rent)
+	}
+	if len(got.Signatures) != 2 || len(rpcClient.sent) != 2 {
+		t.Fatalf("CloseEmptyAtas() sent %d transactions, want 2", len(rpcClient.sent))
+	}
+	for _, tx := range rpcClient.sent {
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 1232 {
+			t.Fatalf("transaction of %d bytes, over the 1232 bytes limit", len(data))
+		}
+	}
+}
+
+func TestAveragePosition(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	other := solana.NewWallet().PublicKey()
+	tests := []struct {
+		name  string
+		fills []TradeEvent
+		want  Position
+	}{
+		{"no fill", nil, Position{}},
+		{"buys", []TradeEvent{
+			{Mint: mint, IsBuy: true, SolAmount: 100000000, TokenAmount: 2000000000000},
+			{Mint: other, IsBuy: true, SolAmount: 500000000, TokenAmount: 1000000000000},
+			{Mint: mint, IsBuy: true, SolAmount: 300000000, TokenAmount: 2000000000000},
+		}, Position{Tokens: 4000000000000, CostBasis: 400000000, AveragePrice: 0.0000001}},
+		{"partial sell", []TradeEvent{
+			{Mint: mint, IsBuy: true, SolAmount: 100000000, TokenAmount: 2000000000000},
+			{Mint: mint, IsBuy: true, SolAmount: 300000000, TokenAmount: 2000000000000},
+			{Mint: mint, IsBuy: false, SolAmount: 1000000000, TokenAmount: 1000000000000},
+		}, Position{Tokens: 3000000000000, CostBasis: 300000000, AveragePrice: 0.0000001}},
+		{"sold out", []TradeEvent{
+			{Mint: mint, IsBuy: true, SolAmount: 100000000, TokenAmount: 2000000000000},
+			{Mint: mint, IsBuy: false, SolAmount: 200000000, TokenAmount: 2000000000000},
+		}, Position{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AveragePosition(mint, tt.fills)
+			if got.Tokens != tt.want.Tokens || got.CostBasis != tt.want.CostBasis || math.Abs(got.AveragePrice-tt.want.AveragePrice) > 1e-15 {
+				t.Fatalf("AveragePosition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// Priority levels of getPriorityFeeEstimate.
+const (
+	PriorityLevelLow      = "Low"
+	PriorityLevelMedium   = "Medium"
+	PriorityLevelHigh     = "High"
+	PriorityLevelVeryHigh = "VeryHigh"
+)
+
+// priorityFeeEstimateRequest holds the parameters of getPriorityFeeEstimate.
+type priorityFeeEstimateRequest struct {
+	AccountKeys []string `json:"accountKeys"`
+	Options     struct {
+		PriorityLevel string `json:"priorityLevel"`
+	} `json:"options"`
+}
+
+type priorityFeeEstimateResponse struct {
+	PriorityFeeEstimate float64 `json:"priorityFeeEstimate"`
+}
+
+// PriorityFeeEstimateProvider returns a PriorityFeeProvider asking the getPriorityFeeEstimate method of the RPC endpoint,
+// offered by providers like Helius and Triton, for the compute unit price of a transaction writing to the accounts
+// at the priority level, e.g. PriorityLevelHigh. The accounts default to the ones of every pump.fun trade.
+// When the endpoint can't estimate it, the provider falls back to the median of the recent prioritization fees
+// of the accounts from rpcClient, or to the default compute unit price of buys if there are none.
+func PriorityFeeEstimateProvider(rpcEndpoint string, rpcClient RPCClient, priorityLevel string, accounts ...solana.PublicKey) PriorityFeeProvider {
+	if len(accounts) == 0 {
+		accounts = []solana.PublicKey{pump.ProgramID, globalPumpFunAddress, pumpFunFeeRecipient}
+	}
+	client := jsonrpc.NewClient(rpcEndpoint)
+	return func(ctx context.Context) (uint64, error) {
+		computeUnitPrice, err := getPriorityFeeEstimate(ctx, client, priorityLevel, accounts)
+		if err == nil {
+			return computeUnitPrice, nil
+		}
+		fees, err := rpcClient.GetRecentPrioritizationFees(ctx, accounts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get recent prioritization fees: %w", err)
+		}
+		if len(fees) == 0 {
+			return defaultBuyComputeUnitPrice, nil
+		}
+		return medianPrioritizationFee(fees), nil
+	}
+}
+
+// getPriorityFeeEstimate calls the getPriorityFeeEstimate method of the client.
+func getPriorityFeeEstimate(ctx context.Context, client jsonrpc.RPCClient, priorityLevel string, accounts []solana.PublicKey) (uint64, error) {
+	var req priorityFeeEstimateRequest
+	for _, account := range accounts {
+		req.AccountKeys = append(req.AccountKeys, account.String())
+	}
+	req.Options.PriorityLevel = priorityLevel
+	var out priorityFeeEstimateResponse
+	if err := client.CallForInto(ctx, &out, "getPriorityFeeEstimate", []interface{}{req}); err != nil {
+		return 0, fmt.Errorf("can't get priority fee estimate: %w", err)
+	}
+	return uint64(math.Ceil(out.PriorityFeeEstimate)), nil
+}
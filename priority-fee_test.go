@@ -0,0 +1,52 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestPriorityFeeEstimateProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported bool
+		want      uint64
+	}{
+		{"estimate", true, 12346},
+		{"fallback", false, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					ID     any
+					Method string
+					Params []priorityFeeEstimateRequest
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "getPriorityFeeEstimate" || req.Params[0].Options.PriorityLevel != PriorityLevelHigh || len(req.Params[0].AccountKeys) != 3 {
+					t.Errorf("unexpected request %+v, error = %v", req, err)
+				}
+				resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+				if tt.supported {
+					resp["result"] = map[string]any{"priorityFeeEstimate": 12345.6}
+				} else {
+					resp["error"] = map[string]any{"code": -32601, "message": "Method not found"}
+				}
+				json.NewEncoder(w).Encode(resp)
+			}))
+			defer server.Close()
+			rpcClient := &mockRPCClient{prioritizationFees: []rpc.PriorizationFeeResult{{PrioritizationFee: 300}, {PrioritizationFee: 100}, {PrioritizationFee: 200}}}
+			got, err := PriorityFeeEstimateProvider(server.URL, rpcClient, PriorityLevelHigh)(context.Background())
+			if err != nil {
+				t.Fatalf("PriorityFeeEstimateProvider() error = %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("PriorityFeeEstimateProvider() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// mockRPCClient serves the accounts from memory, or fails with err if set.
+// The RPC methods it doesn't implement panic, as the embedded interface is nil.
+type mockRPCClient struct {
+	RPCClient
+	accounts           map[solana.PublicKey][]byte
+	prioritizationFees []rpc.PriorizationFeeResult
+	transactions       map[solana.Signature]*rpc.GetTransactionResult
+	statuses           map[solana.Signature]*rpc.SignatureStatusesResult
+	// lamports are the balances of the accounts, 0 if missing.
+	lamports map[solana.PublicKey]uint64
+	// sent are the transactions sent, finalized at once if finalizeSent is set.
+	sent         []*solana.Transaction
+	finalizeSent bool
+	// sentLogs are the logs GetTransaction returns for the transactions sent.
+	sentLogs []string
+	err      error
+	// mu guards calls, sent, statuses and transactions, as the SDK calls the RPC concurrently.
+	mu    sync.Mutex
+	calls int
+}
+
+// call counts a call to the RPC, and returns the count.
+func (m *mockRPCClient) call() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	return m.calls
+}
+
+func (m *mockRPCClient) GetAccountInfoWithOpts(_ context.Context, account solana.PublicKey, _ *rpc.GetAccountInfoOpts) (*rpc.GetAccountInfoResult, error) {
+	m.call()
+	if m.err != nil {
+		return nil, m.err
+	}
+	data, ok := m.accounts[account]
+	if !ok {
+		return nil, rpc.ErrNotFound
+	}
+	return &rpc.GetAccountInfoResult{Value: &rpc.Account{Lamports: m.lamports[account], Data: rpc.DataBytesOrJSONFromBytes(data)}}, nil
+}
+
+func (m *mockRPCClient) GetAccountInfo(ctx context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	return m.GetAccountInfoWithOpts(ctx, account, nil)
+}
+
+func (m *mockRPCClient) GetMultipleAccountsWithOpts(ctx context.Context, accounts []solana.PublicKey, opts *rpc.GetMultipleAccountsOpts) (*rpc.GetMultipleAccountsResult, error) {
+	out := &rpc.GetMultipleAccountsResult{}
+	for _, account := range accounts {
+		info, err := m.GetAccountInfoWithOpts(ctx, account, nil)
+		if errors.Is(err, rpc.ErrNotFound) {
+			out.Value = append(out.Value, nil)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.Value = append(out.Value, info.Value)
+	}
+	return out, nil
+}
+
+func (m *mockRPCClient) SendTransactionWithOpts(_ context.Context, tx *solana.Transaction, _ rpc.TransactionOpts) (solana.Signature, error) {
+	m.call()
+	if m.err != nil {
+		return solana.Signature{}, m.err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, tx)
+	if m.finalizeSent {
+		m.statuses[tx.Signatures[0]] = &rpc.SignatureStatusesResult{ConfirmationStatus: rpc.ConfirmationStatusFinalized}
+	}
+	if m.sentLogs != nil {
+		if m.transactions == nil {
+			m.transactions = map[solana.Signature]*rpc.GetTransactionResult{}
+		}
+		m.transactions[tx.Signatures[0]] = &rpc.GetTransactionResult{Meta: &rpc.TransactionMeta{LogMessages: m.sentLogs}}
+	}
+	return tx.Signatures[0], nil
+}
+
+func (m *mockRPCClient) GetRecentPrioritizationFees(_ context.Context, _ solana.PublicKeySlice) ([]rpc.PriorizationFeeResult, error) {
+	m.call()
+	return m.prioritizationFees, m.err
+}
+
+func (m *mockRPCClient) GetTransaction(_ context.Context, sig solana.Signature, _ *rpc.GetTransactionOpts) (*rpc.GetTransactionResult, error) {
+	m.call()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out, ok := m.transactions[sig]
+	if !ok {
+		return nil, rpc.ErrNotFound
+	}
+	return out, nil
+}
+
+func (m *mockRPCClient) GetLatestBlockhash(_ context.Context, _ rpc.CommitmentType) (*rpc.GetLatestBlockhashResult, error) {
+	calls := m.call()
+	return &rpc.GetLatestBlockhashResult{Value: &rpc.LatestBlockhashResult{Blockhash: solana.Hash{byte(calls)}}}, nil
+}
+
+func (m *mockRPCClient) GetSignatureStatuses(_ context.Context, _ bool, sigs ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	m.call()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := &rpc.GetSignatureStatusesResult{}
+	for _, sig := range sigs {
+		out.Value = append(out.Value, m.statuses[sig])
+	}
+	return out, nil
+}
+
+// bondingCurveAccountData returns the data of a bonding curve account with the given state.
+func bondingCurveAccountData(virtualTokenReserves, virtualSolReserves, realTokenReserves, realSolReserves, tokenTotalSupply uint64, complete bool) []byte {
+	data := make([]byte, 49)
+	copy(data, pump.BondingCurveDiscriminator[:])
+	binary.LittleEndian.PutUint64(data[8:16], virtualTokenReserves)
+	binary.LittleEndian.PutUint64(data[16:24], virtualSolReserves)
+	binary.LittleEndian.PutUint64(data[24:32], realTokenReserves)
+	binary.LittleEndian.PutUint64(data[32:40], realSolReserves)
+	binary.LittleEndian.PutUint64(data[40:48], tokenTotalSupply)
+	if complete {
+		data[48] = 1
+	}
+	return data
+}
@@ -8,130 +8,184 @@ import (
 
 	"github.com/gagliardetto/solana-go"
 	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
-	cb "github.com/gagliardetto/solana-go/programs/compute-budget"
-	"github.com/gagliardetto/solana-go/programs/system"
-	"github.com/gagliardetto/solana-go/programs/token"
-	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/ws"
 	"github.com/zzispp/pumpdotfun-go-sdk/pump"
 )
 
 func SellToken(
-	rpcClient *rpc.Client,
+	ctx context.Context,
+	rpcClient RPCClient,
 	wsClient *ws.Client,
-	user solana.PrivateKey,
+	user Signer,
 	mint solana.PublicKey,
-	sellTokenAmount uint64,
+	sellTokenAmount TokenAmount,
 	slippageBasisPoint uint,
 	all bool,
-) (string, error) {
-	// create priority fee instructions
-	culInst := cb.NewSetComputeUnitLimitInstruction(uint32(250000))
-	cupInst := cb.NewSetComputeUnitPriceInstruction(uint64(10000))
-	instructions := []solana.Instruction{
-		culInst.Build(),
-		cupInst.Build(),
+	opts ...Option,
+) (*TradeResult, error) {
+	o := newOptions(opts)
+	computeUnitPrice, err := getComputeUnitPrice(ctx, o, func() (uint64, error) {
+		return defaultSellComputeUnitPrice, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compute unit price: %w", err)
+	}
+	if o.balanceCheck {
+		payer := user
+		if o.feePayer != nil {
+			payer = o.feePayer
+		}
+		if err := checkBalance(ctx, rpcClient, payer.PublicKey(), priorityFee(computeUnitPrice)+baseFeePerSignature); err != nil {
+			return nil, err
+		}
 	}
 	// get sell instructions
 	sellInstructions, err := getSellInstructions(
+		ctx,
 		rpcClient,
-		user,
+		user.PublicKey(),
 		mint,
-		sellTokenAmount,
+		uint64(sellTokenAmount),
 		slippageBasisPoint,
 		all,
+		o,
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to get sell instructions: %w", err)
+		return nil, fmt.Errorf("failed to get sell instructions: %w", err)
 	}
-	instructions = append(instructions, sellInstructions)
-	// get recent block hash
-	recent, err := rpcClient.GetLatestBlockhash(context.TODO(), rpc.CommitmentFinalized)
+	build := tradeTransactionBuilder(ctx, rpcClient, user, []solana.Instruction{sellInstructions}, o)
+	return sendTrade(ctx, rpcClient, wsClient, computeUnitPrice, 0, build, o)
+}
+
+// SellForSol sells just enough tokens to receive the target SOL, after the pump.fun fee and before the slippage,
+// e.g. to take profits in SOL. The amount is capped at the balance of the user, so it may receive less.
+func SellForSol(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsClient *ws.Client,
+	user Signer,
+	mint solana.PublicKey,
+	targetSolLamports Lamports,
+	slippageBasisPoint uint,
+	opts ...Option,
+) (*TradeResult, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	bondingCurve, err := getBondingCurve(ctx, rpcClient, bondingCurveData.BondingCurve, o)
 	if err != nil {
-		return "", fmt.Errorf("error while getting recent block hash: %w", err)
+		return nil, err
 	}
-	// create new transaction
-	tx, err := solana.NewTransaction(
-		instructions,
-		recent.Value.Blockhash,
-		solana.TransactionPayer(user.PublicKey()),
-	)
+	feeBasisPoints, err := getFeeBasisPoints(ctx, rpcClient, o)
 	if err != nil {
-		return "", fmt.Errorf("error while creating new transaction: %w", err)
+		return nil, err
 	}
-	_, err = tx.Sign(
-		func(key solana.PublicKey) *solana.PrivateKey {
-			if user.PublicKey().Equals(key) {
-				return &user
-			}
-			return nil
-		},
-	)
+	tokens, err := calculateSellAmountForSol(uint64(targetSolLamports), bondingCurve, feeBasisPoints)
 	if err != nil {
-		return "", fmt.Errorf("can't sign transaction: %w", err)
+		return nil, err
 	}
-	// Send transaction:
-	sig, err := rpcClient.SendTransaction(context.TODO(), tx)
+	ata, err := findAssociatedTokenAddress(user.PublicKey(), mint, o.getTokenProgram())
 	if err != nil {
-		return "", fmt.Errorf("can't send transaction: %w", err)
+		return nil, err
 	}
-	return sig.String(), nil
+	balance, err := getTokenBalance(ctx, rpcClient, ata, o)
+	if err != nil {
+		return nil, err
+	}
+	if tokens > balance {
+		o.logger.Warn("not enough tokens to receive the target SOL, selling them all", "tokens", tokens, "balance", balance)
+		tokens = balance
+	}
+	// Quote the sell against the same bonding curve.
+	opts = append(opts, WithBondingCurve(bondingCurve))
+	return SellToken(ctx, rpcClient, wsClient, user, mint, TokenAmount(tokens), slippageBasisPoint, false, opts...)
+}
+
+// SellInstructions returns the pump.fun instructions SellToken would send, without the compute budget instructions.
+// It allows to assemble them with the instructions of other programs in a transaction,
+// leaving the compute budget, the signing and the sending to the caller.
+func SellInstructions(
+	ctx context.Context,
+	rpcClient RPCClient,
+	user solana.PublicKey,
+	mint solana.PublicKey,
+	sellTokenAmount TokenAmount,
+	slippageBasisPoint uint,
+	all bool,
+	opts ...Option,
+) ([]solana.Instruction, error) {
+	sell, err := getSellInstructions(ctx, rpcClient, user, mint, uint64(sellTokenAmount), slippageBasisPoint, all, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return []solana.Instruction{sell}, nil
 }
 
 // getSellInstructions is a function that returns the pump.fun instructions to sell the token
 func getSellInstructions(
-	rpcClient *rpc.Client,
-	user solana.PrivateKey,
+	ctx context.Context,
+	rpcClient RPCClient,
+	user solana.PublicKey,
 	mint solana.PublicKey,
 	sellTokenAmount uint64,
 	slippageBasisPoint uint,
 	all bool,
+	o *options,
 ) (*pump.Instruction, error) {
-	ata, _, err := solana.FindAssociatedTokenAddress(
-		user.PublicKey(),
-		mint,
-	)
+	ata, err := findAssociatedTokenAddress(user, mint, o.getTokenProgram())
 	if err != nil {
-		return nil, fmt.Errorf("failed to derive associated token account: %w", err)
+		return nil, err
 	}
 	if all {
-		tokenAccounts, err := rpcClient.GetTokenAccountBalance(
-			context.TODO(),
-			ata,
-			rpc.CommitmentConfirmed,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("can't get amount of token in balance: %w", err)
-		}
-		amount, err := strconv.Atoi(tokenAccounts.Value.Amount)
+		sellTokenAmount, err = getTokenBalance(ctx, rpcClient, ata, o)
 		if err != nil {
-			return nil, fmt.Errorf("can't convert token amount to integer: %w", err)
+			return nil, err
 		}
-		sellTokenAmount = uint64(amount)
 	}
-	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
 	if err != nil {
 		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
 	}
-	bondingCurve, err := fetchBondingCurve(rpcClient, bondingCurveData.BondingCurve)
+	o.logger.Debug("derived sell addresses", "mint", mint, "bondingCurve", bondingCurveData.BondingCurve, "associatedBondingCurve", bondingCurveData.AssociatedBondingCurve, "ata", ata)
+	bondingCurve, err := getBondingCurve(ctx, rpcClient, bondingCurveData.BondingCurve, o)
+	if err != nil {
+		return nil, err
+	}
+	feeBasisPoints, err := getFeeBasisPoints(ctx, rpcClient, o)
 	if err != nil {
-		return nil, fmt.Errorf("can't fetch bonding curve: %w", err)
+		return nil, err
+	}
+	minSolOutput := calculateSellQuote(sellTokenAmount, bondingCurve, slippageBasisPoint, feeBasisPoints)
+	if o.minSolOutput != nil {
+		// Fail before sending a sell the program would revert, unless the bonding curve of the options,
+		// which may be stale, says so.
+		expected := calculateSellQuote(sellTokenAmount, bondingCurve, 0, feeBasisPoints)
+		if o.bondingCurve == nil && expected.Cmp(new(big.Int).SetUint64(uint64(*o.minSolOutput))) < 0 {
+			return nil, fmt.Errorf("minimum SOL output %d is over the %s lamports the sell would receive: %w", *o.minSolOutput, expected, ErrTooLittleSolReceived)
+		}
+		minSolOutput.SetUint64(uint64(*o.minSolOutput))
 	}
-	percentage := convertSlippageBasisPointsToPercentage(slippageBasisPoint)
-	minSolOutput := calculateSellQuote(sellTokenAmount, bondingCurve, percentage)
+	o.logger.Debug("computed sell quote", "mint", mint, "bondingCurve", bondingCurve, "tokenAmount", sellTokenAmount, "minSolOutput", minSolOutput, "feeBasisPoints", feeBasisPoints)
+	return newSellInstruction(sellTokenAmount, minSolOutput.Uint64(), mint, user, bondingCurveData, ata, o)
+}
+
+// newSellInstruction returns the pump.fun instruction selling the amount of tokens of the mint for at least minSolOutput.
+func newSellInstruction(amount uint64, minSolOutput uint64, mint solana.PublicKey, user solana.PublicKey, bondingCurveData *BondingCurvePublicKeys, ata solana.PublicKey, o *options) (*pump.Instruction, error) {
 	sellInstr := pump.NewSellInstruction(
-		sellTokenAmount,
-		minSolOutput.Uint64(),
+		amount,
+		minSolOutput,
 		globalPumpFunAddress,
 		pumpFunFeeRecipient,
 		mint,
 		bondingCurveData.BondingCurve,
 		bondingCurveData.AssociatedBondingCurve,
 		ata,
-		user.PublicKey(),
-		system.ProgramID,
+		user,
+		pumpFunSystemProgram,
 		associatedtokenaccount.ProgramID,
-		token.ProgramID,
+		o.getTokenProgram(),
 		pumpFunEventAuthority,
 		pump.ProgramID,
 	)
@@ -142,16 +196,39 @@ func getSellInstructions(
 	return sell, nil
 }
 
-// calculateSellQuote calculates how many SOL should be received for selling a specific amount of tokens, given a specific amount of token, bonding curve data, and percentage.
+// SellAccounts returns the ordered accounts of the pump.fun sell instruction of the user for the mint, as SellToken
+// would send it, e.g. to audit them before signing. It doesn't use the RPC.
+func SellAccounts(user solana.PublicKey, mint solana.PublicKey, opts ...Option) ([]*solana.AccountMeta, error) {
+	o := newOptions(opts)
+	bondingCurveData, err := getBondingCurvePublicKeys(mint, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	ata, err := findAssociatedTokenAddress(user, mint, o.getTokenProgram())
+	if err != nil {
+		return nil, err
+	}
+	sell, err := newSellInstruction(0, 0, mint, user, bondingCurveData, ata, o)
+	if err != nil {
+		return nil, err
+	}
+	return sell.Accounts(), nil
+}
+
+// calculateSellQuote calculates how many SOL should be received for selling a specific amount of tokens, given a specific amount of token, bonding curve data, and slippage.
 // tokenAmount is the amount of token you want to sell
 // bondingCurve is the bonding curve data, that will help to calculate the number of sol to get
-// percentage is the slippage, 0.98 means 2% slippage
+// slippageBasisPoint is the slippage, 200 means 2%
+// feeBasisPoints is the fee pump.fun deducts from the SOL output, 100 means 1%
+// The quote is the exact constant-product output, so it already accounts for the price impact of the whole amount:
+// selling it at once or in many smaller sells yields the same SOL, give or take the rounding of each sell.
 func calculateSellQuote(
 	tokenAmount uint64,
 	bondingCurve *BondingCurveData,
-	percentage float64,
+	slippageBasisPoint uint,
+	feeBasisPoints uint64,
 ) *big.Int {
-	amount := big.NewInt(int64(tokenAmount))
+	amount := new(big.Int).SetUint64(tokenAmount)
 
 	// Clone bonding curve data to avoid mutations
 	virtualSolReserves := new(big.Int).Set(bondingCurve.VirtualSolReserves)
@@ -161,9 +238,58 @@ func calculateSellQuote(
 	x := new(big.Int).Mul(virtualSolReserves, amount)
 	y := new(big.Int).Add(virtualTokenReserves, amount)
 	a := new(big.Int).Div(x, y)
-	percentageMultiplier := big.NewFloat(percentage)
-	sol := new(big.Float).SetInt(a)
-	number := new(big.Float).Mul(sol, percentageMultiplier)
-	final, _ := number.Int(nil)
-	return final
+	// Deduct the pump.fun fee, the same way the program does.
+	return applySlippage(afterFee(a, feeBasisPoints), slippageBasisPoint)
+}
+
+// calculateSellAmountForSol returns the fewest tokens to sell to receive the SOL, after the pump.fun fee,
+// inverting calculateSellQuote without slippage.
+func calculateSellAmountForSol(solAmount uint64, bondingCurve *BondingCurveData, feeBasisPoints uint64) (uint64, error) {
+	// The SOL out of the curve before the fee, rounded up, then lowered while the fee rounded down still leaves enough.
+	sol := new(big.Int).SetUint64(solAmount)
+	sol.Mul(sol, big.NewInt(10000))
+	sol.Add(sol, new(big.Int).SetUint64(10000-feeBasisPoints-1))
+	sol.Div(sol, new(big.Int).SetUint64(10000-feeBasisPoints))
+	for sol.Sign() > 0 && afterFee(new(big.Int).Sub(sol, big.NewInt(1)), feeBasisPoints).Uint64() >= solAmount {
+		sol.Sub(sol, big.NewInt(1))
+	}
+	if sol.Cmp(bondingCurve.VirtualSolReserves) >= 0 {
+		return 0, fmt.Errorf("can't receive %d lamports, the bonding curve holds %s virtual lamports", solAmount, bondingCurve.VirtualSolReserves)
+	}
+	// tokens = sol * virtualTokenReserves / (virtualSolReserves - sol), rounded up.
+	x := new(big.Int).Mul(sol, bondingCurve.VirtualTokenReserves)
+	y := new(big.Int).Sub(bondingCurve.VirtualSolReserves, sol)
+	tokens := new(big.Int).Add(x, new(big.Int).Sub(y, big.NewInt(1)))
+	tokens.Div(tokens, y)
+	if !tokens.IsUint64() {
+		return 0, fmt.Errorf("can't receive %d lamports, too many tokens to sell", solAmount)
+	}
+	return tokens.Uint64(), nil
+}
+
+// afterFee returns the SOL left after the pump.fun fee, rounded down like the program does.
+func afterFee(sol *big.Int, feeBasisPoints uint64) *big.Int {
+	fee := new(big.Int).Mul(sol, new(big.Int).SetUint64(feeBasisPoints))
+	fee.Div(fee, big.NewInt(10000))
+	return fee.Sub(sol, fee)
+}
+
+// getTokenBalance returns the token balance of the token account, from the options if set.
+func getTokenBalance(ctx context.Context, rpcClient RPCClient, ata solana.PublicKey, o *options) (uint64, error) {
+	if o.tokenBalance != nil {
+		return uint64(*o.tokenBalance), nil
+	}
+	tokenAccounts, err := rpcClient.GetTokenAccountBalance(
+		ctx,
+		ata,
+		o.getQuoteCommitment(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("can't get amount of token in balance: %w", err)
+	}
+	amount, err := strconv.ParseUint(tokenAccounts.Value.Amount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("can't convert token amount to integer: %w", err)
+	}
+	return amount, nil
 }
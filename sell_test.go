@@ -0,0 +1,217 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestCalculateSellQuote(t *testing.T) {
+	bondingCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(743100000000000),
+		VirtualTokenReserves: big.NewInt(1023000000000000),
+		VirtualSolReserves:   big.NewInt(31466275659),
+	}
+	tests := []struct {
+		name           string
+		tokenAmount    uint64
+		slippage       uint
+		feeBasisPoints uint64
+		want           int64
+	}{
+		// The pump.fun program pays amount * virtualSolReserves / (virtualTokenReserves + amount),
+		// i.e. 304610606 lamports here, minus its fee of 1%, rounded down.
+		{"without slippage", 10000000000000, 0, 100, 301564500},
+		{"without fee", 10000000000000, 0, 0, 304610606},
+		// 98% of 301564500 is exactly 295533210, the integer math doesn't lose a lamport.
+		{"with slippage", 10000000000000, 200, 100, 295533210},
+		// The slippage is rounded down: 98% of 304610606 is 298518393.88.
+		{"with slippage rounded down", 10000000000000, 200, 0, 298518393},
+		{"full slippage", 10000000000000, 10000, 100, 0},
+		{"tiny amount", 1, 0, 100, 0},
+		// Selling more than the supply can't drain the virtual SOL reserves.
+		{"huge amount", math.MaxUint64, 0, 100, 31149885425},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateSellQuote(tt.tokenAmount, bondingCurve, tt.slippage, tt.feeBasisPoints)
+			if got.Int64() != tt.want {
+				t.Fatalf("calculateSellQuote() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateSellQuoteInChunks(t *testing.T) {
+	const (
+		amount = 300000000000000
+		chunks = 1000
+	)
+	bondingCurve := &BondingCurveData{
+		VirtualTokenReserves: big.NewInt(1023000000000000),
+		VirtualSolReserves:   big.NewInt(31466275659),
+	}
+	want := calculateSellQuote(amount, bondingCurve, 0, 0).Int64()
+	// Sell the amount in chunks, moving the reserves after each sell.
+	curve := &BondingCurveData{
+		VirtualTokenReserves: new(big.Int).Set(bondingCurve.VirtualTokenReserves),
+		VirtualSolReserves:   new(big.Int).Set(bondingCurve.VirtualSolReserves),
+	}
+	var got int64
+	for range chunks {
+		sol := calculateSellQuote(amount/chunks, curve, 0, 0)
+		got += sol.Int64()
+		curve.VirtualTokenReserves.Add(curve.VirtualTokenReserves, big.NewInt(amount/chunks))
+		curve.VirtualSolReserves.Sub(curve.VirtualSolReserves, sol)
+	}
+	// Each chunk rounds down by less than a lamport.
+	if got > want || want-got >= chunks {
+		t.Fatalf("selling in %d chunks = %d, want %d", chunks, got, want)
+	}
+}
+
+func TestCalculateSellQuoteInvariant(t *testing.T) {
+	bondingCurve := &BondingCurveData{
+		VirtualTokenReserves: big.NewInt(1023000000000000),
+		VirtualSolReserves:   big.NewInt(31466275659),
+	}
+	invariant := new(big.Int).Mul(bondingCurve.VirtualSolReserves, bondingCurve.VirtualTokenReserves)
+	for _, amount := range []uint64{1, 1000, 10000000000000, 700000000000000} {
+		// The reserves after the sell keep the invariant, rounded in favor of the curve like calculateBuyQuote does:
+		// sol = virtualSolReserves - ceil(invariant / (virtualTokenReserves + amount)).
+		newVirtualTokenReserves := new(big.Int).Add(bondingCurve.VirtualTokenReserves, new(big.Int).SetUint64(amount))
+		newVirtualSolReserves, rem := new(big.Int).QuoRem(invariant, newVirtualTokenReserves, new(big.Int))
+		if rem.Sign() > 0 {
+			newVirtualSolReserves.Add(newVirtualSolReserves, big.NewInt(1))
+		}
+		want := new(big.Int).Sub(bondingCurve.VirtualSolReserves, newVirtualSolReserves)
+		got := calculateSellQuote(amount, bondingCurve, 0, 0)
+		if got.Cmp(want) != 0 {
+			t.Fatalf("calculateSellQuote(%d) = %s, want %s", amount, got, want)
+		}
+	}
+}
+
+func TestGetSellInstructionsMinSolOutput(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		bondingCurveData.BondingCurve: bondingCurveAccountData(1023000000000000, 31466275659, 743100000000000, 0, 1000000000000000, false),
+	}}
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    uint64
+		wantErr error
+	}{
+		{"from slippage", nil, 295533210, nil},
+		{"explicit", []Option{WithMinSolOutput(300000000)}, 300000000, nil},
+		{"exactly the expected output", []Option{WithMinSolOutput(301564500)}, 301564500, nil},
+		{"over the expected output", []Option{WithMinSolOutput(301564501)}, 0, ErrTooLittleSolReceived},
+		// The program checks it against the bonding curve at the time of the sell.
+		{"over the expected output of the bonding curve of the options", []Option{WithMinSolOutput(301564501), WithBondingCurve(&BondingCurveData{
+			VirtualTokenReserves: big.NewInt(1023000000000000),
+			VirtualSolReserves:   big.NewInt(31466275659),
+			RealTokenReserves:    big.NewInt(743100000000000),
+		})}, 301564501, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithFeeBasisPoints(100)}, tt.opts...)
+			sell, err := getSellInstructions(context.Background(), rpcClient, solana.NewWallet().PublicKey(), mint, 10000000000000, 200, false, newOptions(opts))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("getSellInstructions() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getSellInstructions() error = %s", err)
+			}
+			if got := *sell.Impl.(pump.Sell).MinSolOutput; got != tt.want {
+				t.Fatalf("getSellInstructions() minSolOutput = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateSellAmountForSol(t *testing.T) {
+	bondingCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(743100000000000),
+		VirtualTokenReserves: big.NewInt(1023000000000000),
+		VirtualSolReserves:   big.NewInt(31466275659),
+	}
+	tests := []struct {
+		name           string
+		solAmount      uint64
+		feeBasisPoints uint64
+		wantErr        bool
+	}{
+		{"1 lamport", 1, 100, false},
+		{"0.3 SOL", 300000000, 100, false},
+		{"without fee", 300000000, 0, false},
+		{"1 SOL", 1000000000, 100, false},
+		{"more than the curve holds", 40000000000, 100, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := calculateSellAmountForSol(tt.solAmount, bondingCurve, tt.feeBasisPoints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("calculateSellAmountForSol() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// Selling the tokens receives the SOL, and a token less doesn't.
+			if got := calculateSellQuote(tokens, bondingCurve, 0, tt.feeBasisPoints); got.Uint64() < tt.solAmount {
+				t.Fatalf("selling %d tokens receives %s lamports, want at least %d", tokens, got, tt.solAmount)
+			}
+			if got := calculateSellQuote(tokens-1, bondingCurve, 0, tt.feeBasisPoints); got.Uint64() >= tt.solAmount {
+				t.Fatalf("selling %d tokens receives %s lamports, want less than %d", tokens-1, got, tt.solAmount)
+			}
+		})
+	}
+}
+
+func TestSellForSol(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		bondingCurveData.BondingCurve: bondingCurveAccountData(1023000000000000, 31466275659, 743100000000000, 1466275659, 1000000000000000, false),
+	}}
+	tests := []struct {
+		name    string
+		balance TokenAmount
+		want    uint64
+	}{
+		{"enough tokens", 50000000000000, 9947616059485},
+		{"capped at the balance", 5000000000000, 5000000000000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient.sent = nil
+			_, err := SellForSol(context.Background(), rpcClient, nil, solana.NewWallet().PrivateKey, mint, 300000000, 200, WithFeeBasisPoints(100), WithTokenBalance(tt.balance))
+			if err != nil {
+				t.Fatalf("SellForSol() error = %s", err)
+			}
+			tx := rpcClient.sent[0]
+			sell := tx.Message.Instructions[len(tx.Message.Instructions)-1]
+			// The data of the sell is its discriminator, the amount, and the minimum SOL output.
+			if got := binary.LittleEndian.Uint64(sell.Data[8:16]); got != tt.want {
+				t.Fatalf("SellForSol() sold %d tokens, want %d", got, tt.want)
+			}
+		})
+	}
+}
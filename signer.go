@@ -0,0 +1,44 @@
+package pumpdotfunsdk
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Signer signs transactions for a public key, without having to expose its private key,
+// so it can be backed by a hardware wallet, an HSM or a remote service.
+// solana.PrivateKey implements Signer, so it can be passed as is.
+type Signer interface {
+	PublicKey() solana.PublicKey
+	Sign(message []byte) (solana.Signature, error)
+}
+
+var _ Signer = solana.PrivateKey(nil)
+
+// signTransaction sets the signatures of the signers in the transaction.
+// The signatures of the other keys, if any, are left untouched.
+func signTransaction(tx *solana.Transaction, signers ...Signer) error {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable to encode message for signing: %w", err)
+	}
+	signerKeys := tx.Message.AccountKeys[:tx.Message.Header.NumRequiredSignatures]
+	if len(tx.Signatures) == 0 {
+		tx.Signatures = make([]solana.Signature, len(signerKeys))
+	}
+	for i, key := range signerKeys {
+		for _, signer := range signers {
+			if !signer.PublicKey().Equals(key) {
+				continue
+			}
+			sig, err := signer.Sign(message)
+			if err != nil {
+				return fmt.Errorf("failed to sign with key %s: %w", key, err)
+			}
+			tx.Signatures[i] = sig
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package pumpdotfunsdk
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+)
+
+func TestSignTransaction(t *testing.T) {
+	payer := solana.NewWallet().PrivateKey
+	other := solana.NewWallet().PrivateKey
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			system.NewTransferInstruction(1, payer.PublicKey(), other.PublicKey()).Build(),
+			system.NewTransferInstruction(1, other.PublicKey(), payer.PublicKey()).Build(),
+		},
+		solana.Hash{},
+		solana.TransactionPayer(payer.PublicKey()),
+	)
+	if err != nil {
+		t.Fatalf("can't create transaction: %s", err)
+	}
+	if err := signTransaction(tx, other, payer); err != nil {
+		t.Fatalf("signTransaction() error = %s", err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Fatalf("invalid signatures: %s", err)
+	}
+}
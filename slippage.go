@@ -0,0 +1,42 @@
+package pumpdotfunsdk
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// maxSlippageBasisPoint is the full slippage, accepting any price.
+const maxSlippageBasisPoint = 10000
+
+// RequiredBuySlippage returns the minimum slippage, in basis points, that would have let a buy of solAmount go through
+// against the bonding curve, e.g. its state at the slot a buy reverted with ErrTooMuchSolRequired.
+// quotedTokens is the amount of tokens the buy was quoted without slippage, i.e. before the price moved.
+// It returns 0 if the buy goes through without slippage.
+func RequiredBuySlippage(solAmount Lamports, quotedTokens TokenAmount, bondingCurve *BondingCurveData, feeBasisPoints uint64) (uint, error) {
+	quoted := new(big.Int).SetUint64(uint64(quotedTokens))
+	maxSolCost := new(big.Int).SetUint64(uint64(solAmount))
+	goesThrough := func(slippageBasisPoint int) bool {
+		tokens := applySlippage(quoted, uint(slippageBasisPoint))
+		cost, err := chargedBuyCost(tokens.Uint64(), bondingCurve, feeBasisPoints)
+		return err == nil && cost.Cmp(maxSolCost) <= 0
+	}
+	slippageBasisPoint := sort.Search(maxSlippageBasisPoint+1, goesThrough)
+	if slippageBasisPoint > maxSlippageBasisPoint {
+		return 0, errors.New("no slippage lets the buy go through")
+	}
+	return uint(slippageBasisPoint), nil
+}
+
+// RequiredSellSlippage returns the minimum slippage, in basis points, that would have let a sell of tokenAmount go through
+// against the bonding curve, e.g. its state at the slot a sell reverted with ErrTooLittleSolReceived.
+// quotedSol is the SOL the sell was quoted without slippage, after the pump.fun fee, i.e. before the price moved.
+// It returns 0 if the sell goes through without slippage.
+func RequiredSellSlippage(tokenAmount TokenAmount, quotedSol Lamports, bondingCurve *BondingCurveData, feeBasisPoints uint64) uint {
+	quoted := new(big.Int).SetUint64(uint64(quotedSol))
+	received := calculateSellQuote(uint64(tokenAmount), bondingCurve, 0, feeBasisPoints)
+	// The full slippage asks for no SOL, so the search always finds one.
+	return uint(sort.Search(maxSlippageBasisPoint, func(slippageBasisPoint int) bool {
+		return applySlippage(quoted, uint(slippageBasisPoint)).Cmp(received) <= 0
+	}))
+}
@@ -0,0 +1,98 @@
+package pumpdotfunsdk
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRequiredBuySlippage(t *testing.T) {
+	quoteCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(793100000000000),
+		VirtualTokenReserves: big.NewInt(1073000000000000),
+		VirtualSolReserves:   big.NewInt(30000000000),
+	}
+	// Another buy of about 2 SOL landed before the one quoted against quoteCurve.
+	movedCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(724100000000000),
+		VirtualTokenReserves: big.NewInt(1004000000000000),
+		VirtualSolReserves:   big.NewInt(32061752988),
+	}
+	const solAmount, feeBasisPoints = 1000000000, 100
+	quotedTokens := TokenAmount(calculateBuyQuote(solAmount, quoteCurve, 0, feeBasisPoints).Uint64())
+	tests := []struct {
+		name         string
+		bondingCurve *BondingCurveData
+		wantZero     bool
+	}{
+		{"price unchanged", quoteCurve, true},
+		{"price moved up", movedCurve, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RequiredBuySlippage(solAmount, quotedTokens, tt.bondingCurve, feeBasisPoints)
+			if err != nil {
+				t.Fatalf("RequiredBuySlippage() error = %s", err)
+			}
+			if (got == 0) != tt.wantZero {
+				t.Fatalf("RequiredBuySlippage() = %d, want zero %t", got, tt.wantZero)
+			}
+			cost := func(slippageBasisPoint uint) uint64 {
+				tokens := applySlippage(new(big.Int).SetUint64(uint64(quotedTokens)), slippageBasisPoint)
+				sol, err := chargedBuyCost(tokens.Uint64(), tt.bondingCurve, feeBasisPoints)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return sol.Uint64()
+			}
+			if cost(got) > solAmount {
+				t.Fatalf("RequiredBuySlippage() = %d, but the buy costs %d lamports", got, cost(got))
+			}
+			if got > 0 && cost(got-1) <= solAmount {
+				t.Fatalf("RequiredBuySlippage() = %d, but %d is enough", got, got-1)
+			}
+		})
+	}
+}
+
+func TestRequiredSellSlippage(t *testing.T) {
+	quoteCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(743100000000000),
+		VirtualTokenReserves: big.NewInt(1023000000000000),
+		VirtualSolReserves:   big.NewInt(31466275659),
+	}
+	// Another sell landed before the one quoted against quoteCurve.
+	movedCurve := &BondingCurveData{
+		RealTokenReserves:    big.NewInt(773100000000000),
+		VirtualTokenReserves: big.NewInt(1053000000000000),
+		VirtualSolReserves:   big.NewInt(30569800000),
+	}
+	const tokenAmount, feeBasisPoints = 10000000000000, 100
+	// 301564500 lamports, see TestCalculateSellQuote.
+	quotedSol := Lamports(calculateSellQuote(tokenAmount, quoteCurve, 0, feeBasisPoints).Uint64())
+	tests := []struct {
+		name         string
+		bondingCurve *BondingCurveData
+		wantZero     bool
+	}{
+		{"price unchanged", quoteCurve, true},
+		{"price moved down", movedCurve, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RequiredSellSlippage(tokenAmount, quotedSol, tt.bondingCurve, feeBasisPoints)
+			if (got == 0) != tt.wantZero {
+				t.Fatalf("RequiredSellSlippage() = %d, want zero %t", got, tt.wantZero)
+			}
+			received := calculateSellQuote(tokenAmount, tt.bondingCurve, 0, feeBasisPoints)
+			minSolOutput := func(slippageBasisPoint uint) *big.Int {
+				return applySlippage(new(big.Int).SetUint64(uint64(quotedSol)), slippageBasisPoint)
+			}
+			if minSolOutput(got).Cmp(received) > 0 {
+				t.Fatalf("RequiredSellSlippage() = %d, but the sell receives %s lamports", got, received)
+			}
+			if got > 0 && minSolOutput(got-1).Cmp(received) <= 0 {
+				t.Fatalf("RequiredSellSlippage() = %d, but %d is enough", got, got-1)
+			}
+		})
+	}
+}
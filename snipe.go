@@ -0,0 +1,101 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// SnipeCriteria selects the new tokens Snipe buys. The zero fields match any token.
+type SnipeCriteria struct {
+	// Creator is the wallet creating the token.
+	Creator solana.PublicKey
+	// Name is a substring of the name of the token, case-insensitive.
+	Name string
+	// Symbol is the symbol of the token, case-insensitive.
+	Symbol string
+}
+
+// match returns true if the token created by the event meets all the criteria.
+func (c SnipeCriteria) match(event *CreateEvent) bool {
+	if !c.Creator.IsZero() && !c.Creator.Equals(event.User) {
+		return false
+	}
+	if c.Name != "" && !strings.Contains(strings.ToLower(event.Name), strings.ToLower(c.Name)) {
+		return false
+	}
+	if c.Symbol != "" && !strings.EqualFold(c.Symbol, event.Symbol) {
+		return false
+	}
+	return true
+}
+
+// Snipe watches the tokens created on pump.fun, and buys the first one matching the criteria as soon as it's created,
+// like BuyToken would. It returns the create event of the token, and the result of the buy.
+// The new tokens are watched like WatchNewTokens does, through the websocket endpoint.
+// As the buy isn't confirmed through the websocket, WithFeeEscalation polls the signature status.
+// The bonding curve is taken from the create transaction, after the buy of the creator if any,
+// so that the buy doesn't wait for the bonding curve account to be visible to the RPC node.
+func Snipe(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsEndpoint string,
+	user Signer,
+	criteria SnipeCriteria,
+	buyAmountLamports Lamports,
+	slippageBasisPoint uint,
+	opts ...Option,
+) (*CreateEvent, *TradeResult, error) {
+	// Fetch the global account before the launch, to know the initial bonding curve.
+	global, err := getGlobal(ctx, rpcClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get global account: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	// Stop watching once a token is bought.
+	defer cancel()
+	logs, errs, err := watchProgramLogs(ctx, wsEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	for res := range logs {
+		for _, event := range parseCreateEvents(res.Value.Logs) {
+			if !criteria.match(&event) {
+				continue
+			}
+			bondingCurve := bondingCurveAfterTrades(global, event.Mint, parseTradeEvents(res.Value.Logs))
+			result, err := BuyToken(ctx, rpcClient, nil, user, event.Mint, buyAmountLamports, slippageBasisPoint, append([]Option{WithBondingCurve(bondingCurve)}, opts...)...)
+			if err != nil {
+				return &event, nil, fmt.Errorf("can't buy %s: %w", event.Mint, err)
+			}
+			return &event, result, nil
+		}
+	}
+	if err := <-errs; err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, ctx.Err()
+}
+
+// bondingCurveAfterTrades returns the bonding curve of the mint after the trades, e.g. at the end of its create transaction.
+// As the trades hold the virtual reserves, the real ones are derived from how far they moved from the initial ones.
+func bondingCurveAfterTrades(global *pump.Global, mint solana.PublicKey, trades []TradeEvent) *BondingCurveData {
+	bondingCurve := initialBondingCurve(global)
+	for _, trade := range trades {
+		if !trade.Mint.Equals(mint) {
+			continue
+		}
+		// The real reserves move as much as the virtual ones.
+		sold := new(big.Int).Sub(bondingCurve.VirtualTokenReserves, new(big.Int).SetUint64(trade.VirtualTokenReserves))
+		bondingCurve.RealTokenReserves.Sub(bondingCurve.RealTokenReserves, sold)
+		paid := new(big.Int).Sub(new(big.Int).SetUint64(trade.VirtualSolReserves), bondingCurve.VirtualSolReserves)
+		bondingCurve.RealSolReserves.Add(bondingCurve.RealSolReserves, paid)
+		bondingCurve.VirtualTokenReserves.SetUint64(trade.VirtualTokenReserves)
+		bondingCurve.VirtualSolReserves.SetUint64(trade.VirtualSolReserves)
+	}
+	return bondingCurve
+}
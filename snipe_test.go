@@ -0,0 +1,55 @@
+package pumpdotfunsdk
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestSnipeCriteriaMatch(t *testing.T) {
+	creator := solana.NewWallet().PublicKey()
+	event := &CreateEvent{Name: "Pepe Moon", Symbol: "PEPE", User: creator}
+	tests := []struct {
+		name     string
+		criteria SnipeCriteria
+		want     bool
+	}{
+		{"any token", SnipeCriteria{}, true},
+		{"creator", SnipeCriteria{Creator: creator}, true},
+		{"other creator", SnipeCriteria{Creator: solana.NewWallet().PublicKey()}, false},
+		{"name substring", SnipeCriteria{Name: "moon"}, true},
+		{"other name", SnipeCriteria{Name: "doge"}, false},
+		{"symbol", SnipeCriteria{Symbol: "pepe"}, true},
+		{"symbol substring", SnipeCriteria{Symbol: "PEP"}, false},
+		{"all criteria", SnipeCriteria{Creator: creator, Name: "pepe", Symbol: "PEPE"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.criteria.match(event); got != tt.want {
+				t.Fatalf("match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBondingCurveAfterTrades(t *testing.T) {
+	global := &pump.Global{
+		InitialVirtualTokenReserves: 1073000000000000,
+		InitialVirtualSolReserves:   30000000000,
+		InitialRealTokenReserves:    793100000000000,
+		TokenTotalSupply:            1000000000000000,
+	}
+	mint := solana.NewWallet().PublicKey()
+	trades := []TradeEvent{
+		{Mint: solana.NewWallet().PublicKey(), VirtualSolReserves: 1, VirtualTokenReserves: 1},
+		{Mint: mint, VirtualSolReserves: 31000000000, VirtualTokenReserves: 1038387096774194},
+	}
+	got := bondingCurveAfterTrades(global, mint, trades)
+	if got.VirtualSolReserves.Uint64() != 31000000000 || got.VirtualTokenReserves.Uint64() != 1038387096774194 || got.RealTokenReserves.Uint64() != 758487096774194 || got.RealSolReserves.Uint64() != 1000000000 {
+		t.Fatalf("bondingCurveAfterTrades() = %s", got)
+	}
+	if got := bondingCurveAfterTrades(global, mint, nil); got.String() != initialBondingCurve(global).String() {
+		t.Fatalf("bondingCurveAfterTrades() without trade = %s, want the initial bonding curve", got)
+	}
+}
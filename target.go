@@ -0,0 +1,111 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// TargetBuyResult is the result of BuyWithTarget.
+type TargetBuyResult struct {
+	// TradeResult holds the Fill of the buy, with the tokens actually acquired and the SOL actually spent.
+	*TradeResult
+	// BondingCurve is the bonding curve right after the buy. Later trades move it.
+	BondingCurve *BondingCurveData
+	// TargetSolOutput is the SOL selling the tokens acquired must at least receive, after the pump.fun fee of the sell,
+	// to reach the target multiple of the SOL spent, including the pump.fun fee of the buy.
+	TargetSolOutput Lamports
+}
+
+// SellOptions returns the options selling the tokens acquired by the buy at the target with SellToken,
+// without fetching the token balance. SellToken fetches the bonding curve, and fails with ErrTooLittleSolReceived
+// before sending the sell while its price is under the target.
+func (r *TargetBuyResult) SellOptions() []Option {
+	return []Option{
+		WithTokenBalance(r.Fill.TokenAmount),
+		WithMinSolOutput(r.TargetSolOutput),
+	}
+}
+
+// BuyWithTarget buys the token like BuyToken does, waits for the confirmation of the transaction, and returns its fill,
+// with the SOL selling the tokens acquired must receive to make targetMultiple times the SOL spent, e.g. 2 to double it.
+// It allows to sell them at the target as soon as possible with SellToken and the SellOptions of the result.
+func BuyWithTarget(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsClient *ws.Client,
+	user Signer,
+	mint solana.PublicKey,
+	buyAmountLamports Lamports,
+	slippageBasisPoint uint,
+	targetMultiple float64,
+	opts ...Option,
+) (*TargetBuyResult, error) {
+	if targetMultiple <= 0 {
+		return nil, errors.New("target multiple must be positive")
+	}
+	o := newOptions(opts)
+	// Fetch the global account before the buy, to know the initial bonding curve.
+	global, err := getGlobal(ctx, rpcClient)
+	if err != nil {
+		return nil, fmt.Errorf("can't get global account: %w", err)
+	}
+	result, err := BuyToken(ctx, rpcClient, wsClient, user, mint, buyAmountLamports, slippageBasisPoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	confirmation, err := confirmTransaction(ctx, rpcClient, wsClient, result.Signature, o)
+	if err != nil {
+		return nil, fmt.Errorf("can't confirm buy %s: %w", result.Signature, err)
+	}
+	if confirmation.Err != nil {
+		return nil, fmt.Errorf("buy %s failed: %w", result.Signature, revertError(ctx, rpcClient, result.Signature, confirmation.Err))
+	}
+	result.Slot = confirmation.Slot
+	fill, err := getTradeFill(ctx, rpcClient, result.Signature, mint)
+	if err != nil {
+		return nil, err
+	}
+	result.Fill = fill
+	feeBasisPoints := global.FeeBasisPoints
+	if o.feeBasisPoints != nil {
+		feeBasisPoints = *o.feeBasisPoints
+	}
+	return &TargetBuyResult{
+		TradeResult:     result,
+		BondingCurve:    bondingCurveAfterTrades(global, mint, []TradeEvent{*fill}),
+		TargetSolOutput: targetSolOutput(fill.SolAmount, feeBasisPoints, targetMultiple),
+	}, nil
+}
+
+// targetSolOutput returns the SOL a sell must receive to make targetMultiple times the SOL spent by a buy, rounded up.
+// The SOL amount of the buy event leaves out the fee, which the buy paid on top of it.
+func targetSolOutput(solAmount Lamports, feeBasisPoints uint64, targetMultiple float64) Lamports {
+	spent := new(big.Int).SetUint64(uint64(solAmount))
+	fee := new(big.Int).Mul(spent, new(big.Int).SetUint64(feeBasisPoints))
+	spent.Add(spent, fee.Div(fee, big.NewInt(10000)))
+	target := new(big.Float).Mul(new(big.Float).SetInt(spent), big.NewFloat(targetMultiple))
+	out, _ := target.Uint64()
+	if new(big.Float).SetUint64(out).Cmp(target) < 0 {
+		out++
+	}
+	return Lamports(out)
+}
+
+// getTradeFill returns the trade of the mint made by the confirmed transaction.
+func getTradeFill(ctx context.Context, rpcClient RPCClient, sig solana.Signature, mint solana.PublicKey) (*TradeEvent, error) {
+	out, err := getTransaction(ctx, rpcClient, sig)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range parseTradeEvents(out.Meta.LogMessages) {
+		if event.Mint.Equals(mint) {
+			return &event, nil
+		}
+	}
+	return nil, ErrNoTradeEvent
+}
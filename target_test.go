@@ -0,0 +1,147 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestGetTradeFill(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	other := TradeEvent{Mint: solana.NewWallet().PublicKey(), SolAmount: 1, TokenAmount: 1, IsBuy: true}
+	want := TradeEvent{Mint: mint, SolAmount: 100000000, TokenAmount: 3500000000000, IsBuy: true, VirtualSolReserves: 30100000000, VirtualTokenReserves: 1069500000000000}
+	sig := solana.Signature{1}
+	rpcClient := &mockRPCClient{transactions: map[solana.Signature]*rpc.GetTransactionResult{
+		sig: {Meta: &rpc.TransactionMeta{LogMessages: []string{
+			eventLog(t, tradeEventDiscriminator, other),
+			eventLog(t, tradeEventDiscriminator, want),
+		}}},
+	}}
+	got, err := getTradeFill(context.Background(), rpcClient, sig, mint)
+	if err != nil {
+		t.Fatalf("getTradeFill() error = %s", err)
+	}
+	if *got != want {
+		t.Fatalf("getTradeFill() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestTargetSolOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		solAmount      Lamports
+		feeBasisPoints uint64
+		targetMultiple float64
+		want           Lamports
+	}{
+		{"double", 100000000, 100, 2, 202000000},
+		{"half more", 100000000, 100, 1.5, 151500000},
+		{"no fee", 100000000, 0, 2, 200000000},
+		{"rounded up", 3, 100, 1.5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := targetSolOutput(tt.solAmount, tt.feeBasisPoints, tt.targetMultiple); got != tt.want {
+				t.Fatalf("targetSolOutput() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetBuyResultSellOptions(t *testing.T) {
+	mint := solana.NewWallet().PublicKey()
+	fill := &TradeEvent{Mint: mint, SolAmount: 100000000, TokenAmount: 3500000000000, IsBuy: true}
+	global := &pump.Global{InitialVirtualTokenReserves: 1073000000000000, InitialVirtualSolReserves: 30000000000, InitialRealTokenReserves: 793100000000000}
+	result := &TargetBuyResult{TradeResult: &TradeResult{Fill: fill}, BondingCurve: bondingCurveAfterTrades(global, mint, []TradeEvent{*fill}), TargetSolOutput: 202000000}
+	o := newOptions(result.SellOptions())
+	if o.tokenBalance == nil || *o.tokenBalance != fill.TokenAmount {
+		t.Fatalf("SellOptions() token balance = %v, want %d", o.tokenBalance, fill.TokenAmount)
+	}
+	if o.minSolOutput == nil || *o.minSolOutput != result.TargetSolOutput {
+		t.Fatalf("SellOptions() min SOL output = %v, want %d", o.minSolOutput, result.TargetSolOutput)
+	}
+	// The bonding curve of the buy is stale by the time the price reaches the target.
+	if o.bondingCurve != nil {
+		t.Fatal("SellOptions() sets the bonding curve of the buy")
+	}
+}
+
+func TestSellAtTarget(t *testing.T) {
+	global := &pump.Global{
+		InitialVirtualTokenReserves: 1073000000000000,
+		InitialVirtualSolReserves:   30000000000,
+		InitialRealTokenReserves:    793100000000000,
+		TokenTotalSupply:            1000000000000000,
+		FeeBasisPoints:              100,
+	}
+	cachedGlobal.Store(&cachedGlobalAccount{global: global, fetchedAt: time.Now()})
+	defer cachedGlobal.Store(nil)
+	user := solana.NewWallet().PrivateKey
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill := TradeEvent{Mint: mint, SolAmount: 990099009, TokenAmount: 34281150129546, IsBuy: true, User: user.PublicKey()}
+	rpcClient := &mockRPCClient{
+		accounts: map[solana.PublicKey][]byte{
+			bondingCurveData.BondingCurve: bondingCurveAccountData(global.InitialVirtualTokenReserves, global.InitialVirtualSolReserves, global.InitialRealTokenReserves, 0, global.TokenTotalSupply, false),
+		},
+		statuses:     map[solana.Signature]*rpc.SignatureStatusesResult{},
+		finalizeSent: true,
+		sentLogs:     []string{eventLog(t, tradeEventDiscriminator, fill)},
+	}
+	result, err := BuyWithTarget(context.Background(), rpcClient, nil, user, mint, 1000000000, 0, 2)
+	if err != nil {
+		t.Fatalf("BuyWithTarget() error = %s", err)
+	}
+	// Twice the SOL spent, including the fee of the buy.
+	if result.TargetSolOutput != 1999999998 {
+		t.Fatalf("BuyWithTarget() target = %d, want 1999999998", result.TargetSolOutput)
+	}
+	tests := []struct {
+		name         string
+		bondingCurve *BondingCurveData
+		wantErr      error
+	}{
+		{"under the target", result.BondingCurve, ErrTooLittleSolReceived},
+		// Buyers tripled the SOL of the bonding curve.
+		{"over the target", &BondingCurveData{
+			VirtualTokenReserves: big.NewInt(357666666666667),
+			VirtualSolReserves:   big.NewInt(90000000000),
+			RealTokenReserves:    big.NewInt(77766666666667),
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient.accounts[bondingCurveData.BondingCurve] = bondingCurveAccountData(tt.bondingCurve.VirtualTokenReserves.Uint64(), tt.bondingCurve.VirtualSolReserves.Uint64(), tt.bondingCurve.RealTokenReserves.Uint64(), 0, global.TokenTotalSupply, false)
+			sent := len(rpcClient.sent)
+			_, err := SellToken(context.Background(), rpcClient, nil, user, mint, 0, 0, true, result.SellOptions()...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || len(rpcClient.sent) != sent {
+					t.Fatalf("SellToken() error = %v after sending %d transactions, want %v without sending", err, len(rpcClient.sent)-sent, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SellToken() error = %s", err)
+			}
+			tx := rpcClient.sent[len(rpcClient.sent)-1]
+			sell := tx.Message.Instructions[len(tx.Message.Instructions)-1]
+			// The data of the sell is its discriminator, the amount, and the minimum SOL output.
+			if got := TokenAmount(binary.LittleEndian.Uint64(sell.Data[8:16])); got != fill.TokenAmount {
+				t.Fatalf("SellToken() sold %d tokens, want %d", got, fill.TokenAmount)
+			}
+			if got := Lamports(binary.LittleEndian.Uint64(sell.Data[16:24])); got != result.TargetSolOutput {
+				t.Fatalf("SellToken() min SOL output = %d, want %d", got, result.TargetSolOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+)
+
+// The pump.fun program trades native SOL: Buy debits the lamports of the user, and Sell and Withdraw credit them,
+// through the system program. No operation of the bonding curve uses wrapped SOL, so the SDK never creates,
+// syncs or closes a wSOL account. Only the Raydium pool a token migrates to trades against wSOL, which is out of scope.
+
+// TradeResult is the result of BuyToken and SellToken.
+type TradeResult struct {
+	Signature solana.Signature
+	// Attempt is the attempt the transaction was sent at, 1 unless WithFeeEscalation resent it.
+	Attempt int
+	// ComputeUnitPrice is the compute unit price of the transaction, in micro-lamports.
+	ComputeUnitPrice uint64
+	// PriorityFee is the priority fee of the transaction, using the whole compute unit limit.
+	PriorityFee Lamports
+	// Slot is the slot the transaction landed in, only set once it's confirmed, e.g. with WithFeeEscalation.
+	Slot uint64
+	// Fill is the trade the transaction made, with the actual amounts, only set once it's confirmed,
+	// e.g. by ConfirmAndParse.
+	Fill *TradeEvent
+}
+
+// feeEscalation is how the compute unit price is raised when a transaction isn't confirmed in time.
+type feeEscalation struct {
+	attempts            int
+	multiplier          float64
+	maxComputeUnitPrice uint64
+}
+
+// next returns the compute unit price of the next attempt.
+func (e *feeEscalation) next(computeUnitPrice uint64) uint64 {
+	next := uint64(math.Ceil(float64(computeUnitPrice) * e.multiplier))
+	if e.maxComputeUnitPrice > 0 {
+		next = min(next, e.maxComputeUnitPrice)
+	}
+	return next
+}
+
+// tradeTransactionBuilder returns the function building the transaction of a trade with a compute unit price:
+// the compute budget instructions, the instructions of the trade, then the trailing instructions of the options.
+func tradeTransactionBuilder(ctx context.Context, rpcClient RPCClient, user Signer, tradeInstructions []solana.Instruction, o *options) func(uint64) (*solana.Transaction, error) {
+	return func(computeUnitPrice uint64) (*solana.Transaction, error) {
+		instructions := computeBudgetInstructions(computeUnitPrice, o)
+		instructions = append(instructions, tradeInstructions...)
+		instructions = append(instructions, o.trailingInstructions()...)
+		return buildTransaction(ctx, rpcClient, instructions, o, user)
+	}
+}
+
+// sendTrade builds the transaction with the compute unit price, and sends it.
+// With a fee escalation in the options, it waits for the confirmation of the transaction,
+// and resends it with a higher compute unit price on timeout.
+// The compute unit price is capped to maxComputeUnitPrice, unless 0.
+func sendTrade(
+	ctx context.Context,
+	rpcClient RPCClient,
+	wsClient *ws.Client,
+	computeUnitPrice uint64,
+	maxComputeUnitPrice uint64,
+	build func(computeUnitPrice uint64) (*solana.Transaction, error),
+	o *options,
+) (*TradeResult, error) {
+	var results []*TradeResult
+	var txs []*solana.Transaction
+	for attempt := 1; ; attempt++ {
+		if maxComputeUnitPrice > 0 && computeUnitPrice > maxComputeUnitPrice {
+			o.logger.Warn("compute unit price over the max fee fraction, capping it", "computeUnitPrice", computeUnitPrice, "maxComputeUnitPrice", maxComputeUnitPrice)
+			computeUnitPrice = maxComputeUnitPrice
+		}
+		tx, err := build(computeUnitPrice)
+		if err != nil {
+			return nil, err
+		}
+		rebuild := func() (*solana.Transaction, error) {
+			return build(computeUnitPrice)
+		}
+		send := func(tx *solana.Transaction) (solana.Signature, error) {
+			return sendTransaction(ctx, rpcClient, tx, o)
+		}
+		tx, sig, err := sendWithBlockhashRetry(tx, rebuild, send, o)
+		if err != nil {
+			return nil, fmt.Errorf("can't send transaction: %w", MapProgramError(err))
+		}
+		result := &TradeResult{Signature: sig, Attempt: attempt, ComputeUnitPrice: computeUnitPrice, PriorityFee: priorityFee(computeUnitPrice)}
+		o.logger.Info("sent transaction", "signature", sig, "attempt", attempt, "computeUnitPrice", computeUnitPrice)
+		if o.feeEscalation == nil {
+			return result, nil
+		}
+		results = append(results, result)
+		txs = append(txs, tx)
+		confirmation, err := confirmTransaction(ctx, rpcClient, wsClient, sig, o)
+		if err == nil {
+			result.Slot = confirmation.Slot
+			err = withTransactionPrograms(confirmation.Err, tx)
+		}
+		var timeoutErr *ConfirmationTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			if err != nil {
+				return nil, fmt.Errorf("can't confirm transaction: %w", MapProgramError(err))
+			}
+			return result, nil
+		}
+		// A previous attempt may have landed in the meantime.
+		landed, err := landedTrade(ctx, rpcClient, results, txs)
+		if landed != nil || err != nil {
+			return landed, err
+		}
+		if attempt >= o.feeEscalation.attempts {
+			return nil, timeoutErr
+		}
+		computeUnitPrice = o.feeEscalation.next(computeUnitPrice)
+		o.logger.Warn("transaction not confirmed in time, resending it with a higher fee", "signature", sig, "computeUnitPrice", computeUnitPrice)
+	}
+}
+
+// landedTrade returns the first of the trades that landed, nil if none did.
+// txs are the transactions of the trades, to map the error of a failed one.
+func landedTrade(ctx context.Context, rpcClient RPCClient, results []*TradeResult, txs []*solana.Transaction) (*TradeResult, error) {
+	sigs := make([]solana.Signature, len(results))
+	for i, result := range results {
+		sigs[i] = result.Signature
+	}
+	out, err := rpcClient.GetSignatureStatuses(ctx, false, sigs...)
+	if err != nil {
+		// Don't give up on the escalation because of the status check.
+		return nil, nil
+	}
+	for i, status := range out.Value {
+		if status == nil || i >= len(results) {
+			continue
+		}
+		if status.Err != nil {
+			return nil, fmt.Errorf("transaction %s failed: %w", results[i].Signature, MapTransactionError(&transactionError{err: status.Err}, txs[i]))
+		}
+		results[i].Slot = status.Slot
+		return results[i], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,222 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+func TestSendTradeFeeEscalation(t *testing.T) {
+	user := solana.NewWallet().PrivateKey
+	tests := []struct {
+		name        string
+		landed      int
+		wantErr     bool
+		wantPrices  []uint64
+		wantAttempt int
+		maxPrice    uint64
+	}{
+		{"never confirmed", 0, true, []uint64{100, 200, 300}, 0, 0},
+		{"first attempt landed late", 1, false, []uint64{100}, 1, 0},
+		{"capped", 0, true, []uint64{100, 150, 150}, 0, 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient := &mockRPCClient{statuses: map[solana.Signature]*rpc.SignatureStatusesResult{}}
+			o := newOptions([]Option{WithConfirmTimeout(10 * time.Millisecond), WithFeeEscalation(3, 2, 300)})
+			var prices []uint64
+			build := func(computeUnitPrice uint64) (*solana.Transaction, error) {
+				prices = append(prices, computeUnitPrice)
+				instructions := computeBudgetInstructions(computeUnitPrice, o)
+				instructions = append(instructions, system.NewTransferInstruction(1, user.PublicKey(), user.PublicKey()).Build())
+				tx, err := buildTransaction(context.Background(), rpcClient, instructions, o, user)
+				if err == nil && len(prices) == tt.landed {
+					// Lands once its confirmation timed out.
+					rpcClient.statuses[tx.Signatures[0]] = &rpc.SignatureStatusesResult{}
+				}
+				return tx, err
+			}
+			got, err := sendTrade(context.Background(), rpcClient, nil, 100, tt.maxPrice, build, o)
+			if tt.wantErr {
+				var timeoutErr *ConfirmationTimeoutError
+				if !errors.As(err, &timeoutErr) {
+					t.Fatalf("sendTrade() error = %v, want a *ConfirmationTimeoutError", err)
+				}
+			} else if err != nil {
+				t.Fatalf("sendTrade() error = %s", err)
+			} else if got.Attempt != tt.wantAttempt {
+				t.Fatalf("sendTrade() attempt = %d, want %d", got.Attempt, tt.wantAttempt)
+			}
+			if !slices.Equal(prices, tt.wantPrices) {
+				t.Fatalf("sendTrade() compute unit prices = %v, want %v", prices, tt.wantPrices)
+			}
+		})
+	}
+}
+
+func TestBuildBuyTransaction(t *testing.T) {
+	user := solana.NewWallet().PrivateKey
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcClient := &mockRPCClient{accounts: map[solana.PublicKey][]byte{
+		bondingCurveData.BondingCurve: bondingCurveAccountData(1073000000000000, 30000000000, 793100000000000, 0, 1000000000000000, false),
+	}}
+	tx, err := BuildBuyTransaction(context.Background(), rpcClient, user, mint, 100000000, 200, WithFeeBasisPoints(100))
+	if err != nil {
+		t.Fatalf("BuildBuyTransaction() error = %s", err)
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		t.Fatalf("BuildBuyTransaction() signatures: %s", err)
+	}
+	// The compute budget, the ATA creation and the buy.
+	if len(tx.Message.Instructions) != 4 {
+		t.Fatalf("BuildBuyTransaction() has %d instructions, want 4", len(tx.Message.Instructions))
+	}
+	// The bonding curve trades native SOL.
+	for _, key := range tx.Message.AccountKeys {
+		if key.Equals(solana.WrappedSol) {
+			t.Fatal("BuildBuyTransaction() uses wrapped SOL")
+		}
+	}
+}
+
+func TestSendTradeBlockhashNotFound(t *testing.T) {
+	user := solana.NewWallet().PrivateKey
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantBuilds int
+		wantSends  int
+	}{
+		{"fetched blockhash", nil, 2, 2},
+		{"blockhash of the options", []Option{WithBlockhash(solana.Hash{42})}, 1, 1},
+		{"provider with a new blockhash", []Option{WithBlockhashProvider(newBlockhashes())}, 2, 2},
+		{"provider with the same blockhash", []Option{WithBlockhashProvider(func(context.Context) (solana.Hash, error) { return solana.Hash{42}, nil })}, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpcClient := &sendCountingRPCClient{mockRPCClient: &mockRPCClient{err: errors.New("Transaction simulation failed: Blockhash not found")}}
+			o := newOptions(tt.opts)
+			builds := 0
+			build := func(computeUnitPrice uint64) (*solana.Transaction, error) {
+				builds++
+				instructions := []solana.Instruction{system.NewTransferInstruction(1, user.PublicKey(), user.PublicKey()).Build()}
+				return buildTransaction(context.Background(), rpcClient, instructions, o, user)
+			}
+			if _, err := sendTrade(context.Background(), rpcClient, nil, 100, 0, build, o); err == nil {
+				t.Fatal("sendTrade() error = nil, want an error")
+			}
+			if builds != tt.wantBuilds {
+				t.Fatalf("sendTrade() built %d transactions, want %d", builds, tt.wantBuilds)
+			}
+			if rpcClient.sends != tt.wantSends {
+				t.Fatalf("sendTrade() sent %d transactions, want %d", rpcClient.sends, tt.wantSends)
+			}
+		})
+	}
+}
+
+// sendCountingRPCClient counts the transactions sent, including the rejected ones.
+type sendCountingRPCClient struct {
+	*mockRPCClient
+	sends int
+}
+
+func (c *sendCountingRPCClient) SendTransactionWithOpts(ctx context.Context, tx *solana.Transaction, opts rpc.TransactionOpts) (solana.Signature, error) {
+	c.sends++
+	return c.mockRPCClient.SendTransactionWithOpts(ctx, tx, opts)
+}
+
+// newBlockhashes returns a BlockhashProvider returning a new blockhash on each call.
+func newBlockhashes() BlockhashProvider {
+	var calls byte
+	return func(context.Context) (solana.Hash, error) {
+		calls++
+		return solana.Hash{calls}, nil
+	}
+}
+
+func TestTradeAccounts(t *testing.T) {
+	user := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ata, err := findAssociatedTokenAddress(user, mint, solana.TokenProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		accounts func(user, mint solana.PublicKey, opts ...Option) ([]*solana.AccountMeta, error)
+	}{
+		{"buy", BuyAccounts},
+		{"sell", SellAccounts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, err := tt.accounts(user, mint)
+			if err != nil {
+				t.Fatalf("accounts error = %s", err)
+			}
+			want := []solana.PublicKey{globalPumpFunAddress, pumpFunFeeRecipient, mint, bondingCurveData.BondingCurve, bondingCurveData.AssociatedBondingCurve, ata, user}
+			if len(accounts) != 12 {
+				t.Fatalf("got %d accounts, want 12", len(accounts))
+			}
+			for i, key := range want {
+				if !accounts[i].PublicKey.Equals(key) {
+					t.Fatalf("account %d = %s, want %s", i, accounts[i].PublicKey, key)
+				}
+			}
+			if !accounts[6].IsSigner || !accounts[1].IsWritable {
+				t.Fatalf("user signer = %t, fee recipient writable = %t, want both", accounts[6].IsSigner, accounts[1].IsWritable)
+			}
+			if !accounts[len(accounts)-1].PublicKey.Equals(pump.ProgramID) {
+				t.Fatalf("last account = %s, want the pump.fun program", accounts[len(accounts)-1].PublicKey)
+			}
+		})
+	}
+}
+
+func TestGetBlockhash(t *testing.T) {
+	provider := func(context.Context) (solana.Hash, error) {
+		return solana.Hash{7}, nil
+	}
+	failing := func(context.Context) (solana.Hash, error) {
+		return solana.Hash{}, errors.New("no blockhash yet")
+	}
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    solana.Hash
+		wantErr bool
+	}{
+		// The mock returns the number of calls as the blockhash.
+		{"rpc", nil, solana.Hash{1}, false},
+		{"provider", []Option{WithBlockhashProvider(provider)}, solana.Hash{7}, false},
+		{"blockhash over provider", []Option{WithBlockhashProvider(provider), WithBlockhash(solana.Hash{42})}, solana.Hash{42}, false},
+		{"failing provider", []Option{WithBlockhashProvider(failing)}, solana.Hash{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getBlockhash(context.Background(), &mockRPCClient{}, newOptions(tt.opts))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBlockhash() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("getBlockhash() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
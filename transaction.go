@@ -0,0 +1,117 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// buildTransaction fetches a recent blockhash and creates a transaction with the instructions,
+// signed by the signers. The first signer pays for the transaction, unless a fee payer is set in the options.
+func buildTransaction(ctx context.Context, rpcClient RPCClient, instructions []solana.Instruction, o *options, signers ...Signer) (*solana.Transaction, error) {
+	o.progress(StageBuildingTransaction)
+	payer := signers[0]
+	if o.feePayer != nil {
+		payer = o.feePayer
+		signers = append(signers, o.feePayer)
+	}
+	blockhash, err := getBlockhash(ctx, rpcClient, o)
+	if err != nil {
+		return nil, err
+	}
+	if o.durableNonce != nil {
+		instructions = append([]solana.Instruction{o.durableNonce.advanceInstruction()}, instructions...)
+		signers = append(signers, o.durableNonce.authority)
+	}
+	// create new transaction
+	tx, err := solana.NewTransaction(
+		instructions,
+		blockhash,
+		solana.TransactionPayer(payer.PublicKey()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating new transaction: %w", err)
+	}
+	if o.preSignHook != nil {
+		// Let the hook set the signatures of the external signers.
+		tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+		if err := o.preSignHook(tx); err != nil {
+			return nil, fmt.Errorf("pre-sign hook failed: %w", err)
+		}
+	}
+	err = signTransaction(tx, signers...)
+	if err != nil {
+		return nil, fmt.Errorf("can't sign transaction: %w", err)
+	}
+	for i, sig := range tx.Signatures {
+		if sig.IsZero() {
+			return nil, fmt.Errorf("can't sign transaction: missing signature of %s", tx.Message.AccountKeys[i])
+		}
+	}
+	return tx, nil
+}
+
+// BlockhashProvider returns a recent blockhash for the next transaction, e.g. from a cache refreshed in the background,
+// to take the blockhash round-trip off the critical path.
+type BlockhashProvider func(ctx context.Context) (solana.Hash, error)
+
+// getBlockhash returns the blockhash of the transaction: the nonce if the options use a durable nonce,
+// the blockhash of the options if set, the one of their BlockhashProvider if set, a recent blockhash otherwise.
+func getBlockhash(ctx context.Context, rpcClient RPCClient, o *options) (solana.Hash, error) {
+	if o.durableNonce != nil {
+		return fetchNonce(ctx, rpcClient, o.durableNonce.account, o.blockhashCommitment)
+	}
+	if o.blockhash != nil {
+		return *o.blockhash, nil
+	}
+	if o.blockhashProvider != nil {
+		blockhash, err := o.blockhashProvider(ctx)
+		if err != nil {
+			return solana.Hash{}, fmt.Errorf("blockhash provider failed: %w", err)
+		}
+		return blockhash, nil
+	}
+	// get recent block hash
+	recent, err := rpcClient.GetLatestBlockhash(ctx, o.blockhashCommitment)
+	if err != nil {
+		return solana.Hash{}, fmt.Errorf("error while getting recent block hash: %w", err)
+	}
+	return recent.Value.Blockhash, nil
+}
+
+// sendOpts returns the options to send a transaction, simulating it against
+// the same commitment as the one used for its blockhash.
+func sendOpts(o *options) rpc.TransactionOpts {
+	return rpc.TransactionOpts{
+		PreflightCommitment: o.blockhashCommitment,
+	}
+}
+
+// isBlockhashNotFound returns true if the transaction was rejected because the RPC node
+// doesn't know its blockhash, either because it expired, or because the node is lagging behind.
+func isBlockhashNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Blockhash not found")
+}
+
+// sendWithBlockhashRetry sends the transaction, and if its blockhash was not found, rebuilds it with build
+// and sends it once more. It doesn't retry when the blockhash can't change: set by the options,
+// or returned again by their BlockhashProvider. It returns the transaction last sent.
+func sendWithBlockhashRetry[T any](tx *solana.Transaction, build func() (*solana.Transaction, error), send func(*solana.Transaction) (T, error), o *options) (*solana.Transaction, T, error) {
+	out, err := send(tx)
+	if !isBlockhashNotFound(err) || o.blockhash != nil {
+		return tx, out, err
+	}
+	retry, buildErr := build()
+	if buildErr != nil {
+		return tx, out, buildErr
+	}
+	if retry.Message.RecentBlockhash.Equals(tx.Message.RecentBlockhash) {
+		o.logger.Warn("rebuilt transaction has the blockhash not found, not retrying", "blockhash", tx.Message.RecentBlockhash)
+		return tx, out, err
+	}
+	out, err = send(retry)
+	return retry, out, err
+}
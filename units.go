@@ -0,0 +1,41 @@
+package pumpdotfunsdk
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Lamports is an amount of SOL, in lamports. 1 SOL is 1000000000 lamports.
+type Lamports uint64
+
+// TokenAmount is a raw amount of tokens, i.e. in the smallest unit of the token.
+// pump.fun tokens have 6 decimals, so 1 token is a TokenAmount of 1000000.
+type TokenAmount uint64
+
+// tokenUnit is the TokenAmount of 1 token.
+const tokenUnit = 1000000
+
+// SolToLamports converts an amount of SOL to lamports, e.g. 0.1 SOL to 100000000 lamports.
+// Negative and NaN amounts are converted to 0.
+func SolToLamports(sol float64) Lamports {
+	if math.IsNaN(sol) || sol <= 0 {
+		return 0
+	}
+	return Lamports(math.Round(sol * float64(solana.LAMPORTS_PER_SOL)))
+}
+
+// LamportsToSol converts an amount of lamports to SOL, e.g. 100000000 lamports to 0.1 SOL.
+func LamportsToSol(lamports Lamports) float64 {
+	return float64(lamports) / float64(solana.LAMPORTS_PER_SOL)
+}
+
+// SlippagePercent converts a slippage in percent, e.g. 2.5 for 2.5%, to the basis points taken by
+// BuyToken and SellToken, e.g. 250. It returns an error if the slippage isn't between 0 and 100%.
+func SlippagePercent(percent float64) (uint, error) {
+	if math.IsNaN(percent) || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("slippage %v%% is not between 0 and 100%%", percent)
+	}
+	return uint(math.Round(percent * 100)), nil
+}
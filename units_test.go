@@ -0,0 +1,50 @@
+package pumpdotfunsdk
+
+import "testing"
+
+func TestSolToLamports(t *testing.T) {
+	tests := []struct {
+		sol  float64
+		want Lamports
+	}{
+		{0.1, 100000000},
+		{0.0001, 100000},
+		{1.5, 1500000000},
+		{0, 0},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		if got := SolToLamports(tt.sol); got != tt.want {
+			t.Errorf("SolToLamports(%v) = %d, want %d", tt.sol, got, tt.want)
+		}
+		if tt.want > 0 {
+			if got := LamportsToSol(tt.want); got != tt.sol {
+				t.Errorf("LamportsToSol(%d) = %v, want %v", tt.want, got, tt.sol)
+			}
+		}
+	}
+}
+
+func TestSlippagePercent(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    uint
+		wantErr bool
+	}{
+		{2.5, 250, false},
+		{0, 0, false},
+		{100, 10000, false},
+		{0.01, 1, false},
+		{-1, 0, true},
+		{100.5, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := SlippagePercent(tt.percent)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SlippagePercent(%v) error = %v, wantErr %v", tt.percent, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("SlippagePercent(%v) = %d, want %d", tt.percent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// base58Alphabet holds the characters a public key can be made of.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// vanityProgressInterval is how often GenerateVanityMint reports its progress.
+var vanityProgressInterval = time.Second
+
+// VanityProgress is the progress of GenerateVanityMint.
+type VanityProgress struct {
+	// Attempts is the number of keys generated so far.
+	Attempts uint64
+	// AttemptsPerSecond is the rate of the last interval.
+	AttemptsPerSecond float64
+}
+
+// GenerateVanityMint generates mint keys with workers goroutines until the public key of one ends with the suffix,
+// like the "pump" suffix of the mints created on pump.fun, and returns it to pass to CreateToken.
+// Every 4 more characters of suffix take about 11M times more attempts.
+// If progress isn't nil, it receives the progress every second, skipped if the channel isn't ready.
+// All the workers are stopped before it returns, on a match or when the context is done.
+func GenerateVanityMint(ctx context.Context, suffix string, workers int, progress chan<- VanityProgress) (solana.PrivateKey, error) {
+	if suffix == "" {
+		return nil, fmt.Errorf("empty vanity suffix")
+	}
+	for _, c := range suffix {
+		if !strings.ContainsRune(base58Alphabet, c) {
+			return nil, fmt.Errorf("invalid vanity suffix %q: %q is not a base58 character", suffix, c)
+		}
+	}
+	workers = max(workers, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var attempts atomic.Uint64
+	found := make(chan solana.PrivateKey, 1)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				key, err := solana.NewRandomPrivateKey()
+				if err != nil {
+					continue
+				}
+				attempts.Add(1)
+				if strings.HasSuffix(key.PublicKey().String(), suffix) {
+					select {
+					case found <- key:
+						cancel()
+					default:
+					}
+					return
+				}
+			}
+		}()
+	}
+	// Don't leave a worker running once we return.
+	defer wg.Wait()
+
+	ticker := time.NewTicker(vanityProgressInterval)
+	defer ticker.Stop()
+	last, lastTime := uint64(0), time.Now()
+	for {
+		select {
+		case key := <-found:
+			return key, nil
+		case <-ctx.Done():
+			// A worker may have found a key as the context was canceled.
+			select {
+			case key := <-found:
+				return key, nil
+			default:
+				return nil, ctx.Err()
+			}
+		case now := <-ticker.C:
+			if progress == nil {
+				continue
+			}
+			total := attempts.Load()
+			p := VanityProgress{Attempts: total, AttemptsPerSecond: float64(total-last) / now.Sub(lastTime).Seconds()}
+			last, lastTime = total, now
+			select {
+			case progress <- p:
+			default:
+			}
+		}
+	}
+}
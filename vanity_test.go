@@ -0,0 +1,50 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateVanityMint(t *testing.T) {
+	defer func(interval time.Duration) { vanityProgressInterval = interval }(vanityProgressInterval)
+	vanityProgressInterval = time.Millisecond
+	goroutines := runtime.NumGoroutine()
+
+	progress := make(chan VanityProgress, 100)
+	key, err := GenerateVanityMint(context.Background(), "ab", 4, progress)
+	if err != nil {
+		t.Fatalf("GenerateVanityMint() error = %s", err)
+	}
+	if !strings.HasSuffix(key.PublicKey().String(), "ab") {
+		t.Fatalf("GenerateVanityMint() = %s, want the suffix %q", key.PublicKey(), "ab")
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Fatalf("%d goroutines left running, want %d", n, goroutines)
+	}
+}
+
+func TestGenerateVanityMintCanceled(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// Far too long to be found in time.
+	if _, err := GenerateVanityMint(ctx, "pumpfunpump", 2, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GenerateVanityMint() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Fatalf("%d goroutines left running, want %d", n, goroutines)
+	}
+}
+
+func TestGenerateVanityMintInvalidSuffix(t *testing.T) {
+	// 0, O, I and l aren't base58 characters.
+	for _, suffix := range []string{"", "p0mp", "Op", "Il"} {
+		if _, err := GenerateVanityMint(context.Background(), suffix, 1, nil); err == nil {
+			t.Fatalf("GenerateVanityMint(%q) error = nil, want error", suffix)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/zzispp/pumpdotfun-go-sdk/pump"
+)
+
+// The watchers reconnect to the websocket endpoint when the connection drops, waiting between two attempts
+// from minReconnectBackoff, doubled after each failed attempt up to maxReconnectBackoff.
+// They give up after maxReconnectAttempts failed attempts in a row.
+const (
+	minReconnectBackoff  = 500 * time.Millisecond
+	maxReconnectBackoff  = 30 * time.Second
+	maxReconnectAttempts = 10
+)
+
+// WatchBondingCurve streams the bonding curve data of the mint every time its bonding curve account changes,
+// through a websocket connection to the endpoint, reconnected when it drops.
+// The channels are closed when the context is canceled, or after the error channel receives the error
+// that stopped the watch, e.g. when the endpoint can't be reconnected to.
+func WatchBondingCurve(ctx context.Context, wsEndpoint string, mint solana.PublicKey) (<-chan *BondingCurveData, <-chan error, error) {
+	bondingCurveData, err := getBondingCurveAndAssociatedBondingCurve(mint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get bonding curve data: %w", err)
+	}
+	accounts, errs, err := watchSubscription(ctx, wsEndpoint, func(wsClient *ws.Client) (subscription[*ws.AccountResult], error) {
+		sub, err := wsClient.AccountSubscribeWithOpts(bondingCurveData.BondingCurve, rpc.CommitmentConfirmed, solana.EncodingBase64)
+		if err != nil {
+			return nil, fmt.Errorf("can't subscribe to bonding curve account: %w", err)
+		}
+		return sub, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan *BondingCurveData)
+	go func() {
+		defer close(out)
+		for res := range accounts {
+			bondingCurve, err := decodeBondingCurve(res.Value.Data.GetBinary())
+			if err != nil {
+				continue
+			}
+			select {
+			case out <- bondingCurve:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errs, nil
+}
+
+// WatchMintForCompletion sends once on the channel when the bonding curve of the mint completes,
+// i.e. when the token migrates to Raydium, and closes it. The channels are closed without sending
+// when the context is canceled, or after the error channel receives the error that stopped the watch.
+// Only changes of the bonding curve are watched, so check whether it's already complete beforehand.
+func WatchMintForCompletion(ctx context.Context, wsEndpoint string, mint solana.PublicKey) (<-chan struct{}, <-chan error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	bondingCurves, errs, err := WatchBondingCurve(ctx, wsEndpoint, mint)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	out := make(chan struct{}, 1)
+	go func() {
+		defer close(out)
+		// Stop watching the bonding curve once complete.
+		defer cancel()
+		for bondingCurve := range bondingCurves {
+			if bondingCurve.Complete {
+				out <- struct{}{}
+				return
+			}
+		}
+	}()
+	return out, errs, nil
+}
+
+// WatchTrades streams the buys and sells of the mint, decoded from the pump.fun program logs,
+// through a websocket connection to the endpoint, reconnected when it drops.
+// The channels are closed when the context is canceled, or after the error channel receives the error
+// that stopped the watch, e.g. when the endpoint can't be reconnected to.
+func WatchTrades(ctx context.Context, wsEndpoint string, mint solana.PublicKey) (<-chan TradeEvent, <-chan error, error) {
+	logs, errs, err := watchProgramLogs(ctx, wsEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan TradeEvent)
+	go func() {
+		defer close(out)
+		for res := range logs {
+			for _, event := range parseTradeEvents(res.Value.Logs) {
+				if !event.Mint.Equals(mint) {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, errs, nil
+}
+
+// WatchNewTokens streams the tokens created on pump.fun, decoded from the program logs,
+// through a websocket connection to the endpoint, reconnected when it drops.
+// The channels are closed when the context is canceled, or after the error channel receives the error
+// that stopped the watch, e.g. when the endpoint can't be reconnected to.
+func WatchNewTokens(ctx context.Context, wsEndpoint string) (<-chan CreateEvent, <-chan error, error) {
+	logs, errs, err := watchProgramLogs(ctx, wsEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan CreateEvent)
+	go func() {
+		defer close(out)
+		for res := range logs {
+			for _, event := range parseCreateEvents(res.Value.Logs) {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, errs, nil
+}
+
+// watchProgramLogs streams the logs of the successful transactions mentioning the pump.fun program,
+// like watchSubscription.
+func watchProgramLogs(ctx context.Context, wsEndpoint string) (<-chan *ws.LogResult, <-chan error, error) {
+	logs, errs, err := watchSubscription(ctx, wsEndpoint, func(wsClient *ws.Client) (subscription[*ws.LogResult], error) {
+		sub, err := wsClient.LogsSubscribeMentions(pump.ProgramID, rpc.CommitmentConfirmed)
+		if err != nil {
+			return nil, fmt.Errorf("can't subscribe to pump.fun logs: %w", err)
+		}
+		return sub, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan *ws.LogResult)
+	// The same transaction can be notified twice, e.g. across reconnections.
+	seen := newSignatureSet(maxSeenSignatures)
+	go func() {
+		defer close(out)
+		for res := range logs {
+			// Failed transactions don't emit any event.
+			if res.Value.Err != nil || !seen.add(res.Value.Signature) {
+				continue
+			}
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, errs, nil
+}
+
+// subscription is a websocket subscription, notifying values of type T.
+type subscription[T any] interface {
+	Response() <-chan T
+	Err() <-chan error
+	Unsubscribe()
+}
+
+// watchSubscription connects to the websocket endpoint, subscribes with subscribe, and streams the notifications.
+// When the connection drops, it reconnects and subscribes again with backoff.
+// The error channel receives the error if it can't reconnect, and both channels are closed
+// when the context is canceled, or after the error.
+func watchSubscription[T any](ctx context.Context, wsEndpoint string, subscribe func(*ws.Client) (subscription[T], error)) (<-chan T, <-chan error, error) {
+	wsClient, sub, err := connectAndSubscribe(ctx, wsEndpoint, subscribe)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan T)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(out)
+		for {
+			err := forwardNotifications(ctx, sub, out)
+			sub.Unsubscribe()
+			wsClient.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			wsClient, sub, err = reconnectAndSubscribe(ctx, wsEndpoint, subscribe, err)
+			if err != nil {
+				if ctx.Err() == nil {
+					errs <- err
+				}
+				return
+			}
+		}
+	}()
+	return out, errs, nil
+}
+
+// forwardNotifications sends the notifications of the subscription to out,
+// until the context is canceled or the subscription fails, and returns the error of the subscription.
+func forwardNotifications[T any](ctx context.Context, sub subscription[T], out chan<- T) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case res := <-sub.Response():
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// reconnectAndSubscribe connects to the websocket endpoint and subscribes with subscribe, with backoff,
+// after the subscription failed with cause.
+func reconnectAndSubscribe[T any](ctx context.Context, wsEndpoint string, subscribe func(*ws.Client) (subscription[T], error), cause error) (*ws.Client, subscription[T], error) {
+	backoff := minReconnectBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		wsClient, sub, err := connectAndSubscribe(ctx, wsEndpoint, subscribe)
+		if err == nil {
+			return wsClient, sub, nil
+		}
+		if attempt == maxReconnectAttempts {
+			return nil, nil, fmt.Errorf("can't reconnect after %d attempts: %w", attempt, errors.Join(cause, err))
+		}
+		backoff = min(2*backoff, maxReconnectBackoff)
+	}
+}
+
+// connectAndSubscribe connects to the websocket endpoint, and subscribes with subscribe.
+func connectAndSubscribe[T any](ctx context.Context, wsEndpoint string, subscribe func(*ws.Client) (subscription[T], error)) (*ws.Client, subscription[T], error) {
+	wsClient, err := ws.Connect(ctx, wsEndpoint)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't connect to websocket: %w", err)
+	}
+	sub, err := subscribe(wsClient)
+	if err != nil {
+		wsClient.Close()
+		return nil, nil, err
+	}
+	return wsClient, sub, nil
+}
+
+// maxSeenSignatures is the number of recent signatures the watchers remember, to skip duplicate notifications.
+const maxSeenSignatures = 10000
+
+// signatureSet remembers the last signatures added to it, up to its capacity.
+type signatureSet struct {
+	signatures map[solana.Signature]struct{}
+	// order holds the signatures in the order they were added, as a ring buffer.
+	order []solana.Signature
+	next  int
+}
+
+func newSignatureSet(capacity int) *signatureSet {
+	return &signatureSet{
+		signatures: make(map[solana.Signature]struct{}, capacity),
+		order:      make([]solana.Signature, 0, capacity),
+	}
+}
+
+// add adds the signature, forgetting the oldest one if full, and returns false if it was already there.
+func (s *signatureSet) add(sig solana.Signature) bool {
+	if _, ok := s.signatures[sig]; ok {
+		return false
+	}
+	if len(s.order) < cap(s.order) {
+		s.order = append(s.order, sig)
+	} else {
+		delete(s.signatures, s.order[s.next])
+		s.order[s.next] = sig
+		s.next = (s.next + 1) % len(s.order)
+	}
+	s.signatures[sig] = struct{}{}
+	return true
+}
@@ -0,0 +1,83 @@
+package pumpdotfunsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gorilla/websocket"
+)
+
+func TestSignatureSet(t *testing.T) {
+	sigs := []solana.Signature{{1}, {2}, {3}}
+	set := newSignatureSet(2)
+	for _, sig := range sigs[:2] {
+		if !set.add(sig) {
+			t.Fatalf("add(%s) = false, want true", sig)
+		}
+	}
+	if set.add(sigs[1]) {
+		t.Fatalf("add(%s) twice = true, want false", sigs[1])
+	}
+	// The oldest signature is forgotten once full.
+	if !set.add(sigs[2]) || !set.add(sigs[0]) {
+		t.Fatal("add() of a forgotten signature = false, want true")
+	}
+	if set.add(sigs[2]) {
+		t.Fatalf("add(%s) twice = true, want false", sigs[2])
+	}
+}
+
+func TestWatchProgramLogsReconnects(t *testing.T) {
+	sig := solana.Signature{1}
+	notification := fmt.Sprintf(`{"jsonrpc":"2.0","method":"logsNotification","params":{"result":{"context":{"slot":1},"value":{"signature":%q,"err":null,"logs":[]}},"subscription":1}}`, sig)
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var req struct{ ID uint64 }
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","result":1,"id":%d}`, req.ID)))
+		// The first connection drops before notifying anything, the second one notifies the same transaction twice.
+		if connections.Add(1) == 1 {
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(notification))
+		conn.WriteMessage(websocket.TextMessage, []byte(notification))
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	logs, errs, err := watchProgramLogs(ctx, "ws"+strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatalf("watchProgramLogs() error = %s", err)
+	}
+	res, ok := <-logs
+	if !ok {
+		t.Fatalf("watchProgramLogs() closed, error = %v", <-errs)
+	}
+	if res.Value.Signature != sig {
+		t.Fatalf("watchProgramLogs() notified %s, want %s", res.Value.Signature, sig)
+	}
+	cancel()
+	for res := range logs {
+		t.Fatalf("watchProgramLogs() notified %s again", res.Value.Signature)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("watchProgramLogs() error = %s after the context is canceled", err)
+	}
+}